@@ -3,6 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"time"
 )
 
 // BoolArg describes a boolean argument
@@ -17,14 +18,33 @@ type BoolArg struct {
 	Value bool
 	// A boolean to display or not the current value of the argument in the help message
 	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(bool) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (a BoolArg) value() bool {
 	return a.Value
 }
 
+func (a BoolArg) validate(v bool) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
 // StringArg describes a string argument
 type StringArg struct {
 	// The argument name as will be shown in help messages
@@ -37,14 +57,33 @@ type StringArg struct {
 	Value string
 	// A boolean to display or not the current value of the argument in the help message
 	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(string) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (a StringArg) value() string {
 	return a.Value
 }
 
+func (a StringArg) validate(v string) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
 // IntArg describes an int argument
 type IntArg struct {
 	// The argument name as will be shown in help messages
@@ -57,14 +96,33 @@ type IntArg struct {
 	Value int
 	// A boolean to display or not the current value of the argument in the help message
 	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(int) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (a IntArg) value() int {
 	return a.Value
 }
 
+func (a IntArg) validate(v int) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
 // StringsArg describes a string slice argument
 type StringsArg struct {
 	// The argument name as will be shown in help messages
@@ -78,14 +136,33 @@ type StringsArg struct {
 	Value []string
 	// A boolean to display or not the current value of the argument in the help message
 	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func([]string) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (a StringsArg) value() []string {
 	return a.Value
 }
 
+func (a StringsArg) validate(v []string) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
 // IntsArg describes an int slice argument
 type IntsArg struct {
 	// The argument name as will be shown in help messages
@@ -99,14 +176,328 @@ type IntsArg struct {
 	Value []int
 	// A boolean to display or not the current value of the argument in the help message
 	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func([]int) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (a IntsArg) value() []int {
 	return a.Value
 }
 
+func (a IntsArg) validate(v []int) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+// FloatArg describes a float64 argument
+type FloatArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument
+	EnvVar string
+	// The argument's initial value
+	Value float64
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(float64) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a FloatArg) value() float64 {
+	return a.Value
+}
+
+func (a FloatArg) validate(v float64) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+// FloatsArg describes a float64 slice argument
+type FloatsArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument.
+	// The env variable should contain a comma separated list of values
+	EnvVar string
+	// The argument's initial value
+	Value []float64
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func([]float64) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a FloatsArg) value() []float64 {
+	return a.Value
+}
+
+func (a FloatsArg) validate(v []float64) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+// DurationArg describes a time.Duration argument
+type DurationArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument
+	EnvVar string
+	// The argument's initial value
+	Value time.Duration
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(time.Duration) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a DurationArg) value() time.Duration {
+	return a.Value
+}
+
+func (a DurationArg) validate(v time.Duration) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+// DurationsArg describes a time.Duration slice argument
+type DurationsArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument.
+	// The env variable should contain a comma separated list of values
+	EnvVar string
+	// The argument's initial value
+	Value []time.Duration
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func([]time.Duration) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a DurationsArg) value() []time.Duration {
+	return a.Value
+}
+
+func (a DurationsArg) validate(v []time.Duration) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+// EnumArg describes a string argument which only accepts a predefined set of values
+type EnumArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument
+	EnvVar string
+	// The argument's initial value. If not empty, it must be one of the Choices
+	Value string
+	// The values accepted by this argument
+	Choices []string
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(string) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a EnumArg) value() string {
+	return a.Value
+}
+
+func (a EnumArg) validate(v string) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+func (a EnumArg) choices() []string {
+	return a.Choices
+}
+
+// EnumsArg describes a string slice argument which only accepts a predefined set of values
+type EnumsArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument.
+	// The env variable should contain a comma separated list of values
+	EnvVar string
+	// The argument's initial value. Every value must be one of the Choices
+	Value []string
+	// The values accepted by this argument
+	Choices []string
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func([]string) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a EnumsArg) value() []string {
+	return a.Value
+}
+
+func (a EnumsArg) validate(v []string) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+func (a EnumsArg) choices() []string {
+	return a.Choices
+}
+
+// StringMapArg describes a string map argument, where every value is passed as key=value
+type StringMapArg struct {
+	// The argument name as will be shown in help messages
+	Name string
+	// The argument description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this argument.
+	// The env variable should contain a comma separated list of key=value pairs
+	EnvVar string
+	// The argument's initial value
+	Value map[string]string
+	// If true, passing the same key more than once is an error. Otherwise, the last value wins
+	RejectDuplicateKeys bool
+	// A boolean to display or not the current value of the argument in the help message
+	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(map[string]string) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (a StringMapArg) value() map[string]string {
+	return a.Value
+}
+
+func (a StringMapArg) validate(v map[string]string) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
+func (a StringMapArg) rejectDuplicateKeys() bool {
+	return a.RejectDuplicateKeys
+}
+
 // VarArg describes an argument where the type and format of the value is controlled by the developer
 type VarArg struct {
 	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
@@ -120,14 +511,33 @@ type VarArg struct {
 	Value flag.Value
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the argument is listed under, e.g. "Output arguments". Ungrouped arguments are listed under "Arguments"
+	Group string
+	// A boolean to hide the argument from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A message to mark the argument as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this arg was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the argument's value once it was set from the call arguments or from an env variable
+	Validate func(flag.Value) error
+	// A function returning the completion candidates for the argument's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (a VarArg) value() flag.Value {
 	return a.Value
 }
 
+func (a VarArg) validate(v flag.Value) error {
+	if a.Validate == nil {
+		return nil
+	}
+	return a.Validate(v)
+}
+
 /*
 BoolArg defines a boolean argument on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
 
@@ -193,6 +603,103 @@ func (c *Cmd) IntsArg(name string, value []int, desc string) *[]int {
 	})
 }
 
+/*
+FloatArg defines a float64 argument on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The result should be stored in a variable (a pointer to a float64) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) FloatArg(name string, value float64, desc string) *float64 {
+	return c.Float(FloatArg{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+FloatsArg defines a float64 slice argument on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The result should be stored in a variable (a pointer to a float64 slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) FloatsArg(name string, value []float64, desc string) *[]float64 {
+	return c.Floats(FloatsArg{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+DurationArg defines a time.Duration argument on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The result should be stored in a variable (a pointer to a time.Duration) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) DurationArg(name string, value time.Duration, desc string) *time.Duration {
+	return c.Duration(DurationArg{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+DurationsArg defines a time.Duration slice argument on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The result should be stored in a variable (a pointer to a time.Duration slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) DurationsArg(name string, value []time.Duration, desc string) *[]time.Duration {
+	return c.Durations(DurationsArg{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+EnumArg defines a string argument on the command c named `name`, with an initial value of `value`, a list of accepted values `choices`
+and a description of `desc` which will be used in help messages.
+
+The result should be stored in a variable (a pointer to a string) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) EnumArg(name string, value string, choices []string, desc string) *string {
+	return c.Enum(EnumArg{
+		Name:    name,
+		Value:   value,
+		Choices: choices,
+		Desc:    desc,
+	})
+}
+
+/*
+EnumsArg defines a string slice argument on the command c named `name`, with an initial value of `value`, a list of accepted values `choices`
+and a description of `desc` which will be used in help messages.
+
+The result should be stored in a variable (a pointer to a string slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) EnumsArg(name string, value []string, choices []string, desc string) *[]string {
+	return c.Enums(EnumsArg{
+		Name:    name,
+		Value:   value,
+		Choices: choices,
+		Desc:    desc,
+	})
+}
+
+/*
+StringMapArg defines a string map argument on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+Every value of the argument is a key=value pair, the last value winning if a key is repeated.
+
+The result should be stored in a variable (a pointer to a string map) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) StringMapArg(name string, value map[string]string, desc string) *map[string]string {
+	return c.StringMap(StringMapArg{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
 /*
 VarArg defines an argument where the type and format is controlled by the developer on the command c named `name` and a description of `desc` which will be used in help messages.
 
@@ -203,13 +710,21 @@ func (c *Cmd) VarArg(name string, value flag.Value, desc string) {
 }
 
 type arg struct {
-	name            string
-	desc            string
-	envVar          string
-	hideValue       bool
-	valueSetFromEnv bool
-	valueSetByUser  *bool
-	value           flag.Value
+	name              string
+	desc              string
+	envVar            string
+	hideValue         bool
+	group             string
+	hidden            bool
+	deprecated        string
+	deprecatedEnvVars map[string]string
+	valueSource       ValueSource
+	valueEnvVar       string
+	configErr         error
+	valueSetByUser    *bool
+	value             flag.Value
+	validate          func() error
+	complete          func(prefix string) []string
 }
 
 func (a *arg) String() string {
@@ -217,7 +732,10 @@ func (a *arg) String() string {
 }
 
 func (c *Cmd) mkArg(arg arg) {
-	arg.valueSetFromEnv = setFromEnv(arg.value, arg.envVar)
+	if arg.valueEnvVar = setFromEnv(arg.value, arg.envVar); arg.valueEnvVar != "" {
+		arg.valueSource = ValueSourceEnv
+	}
+	c.setArgFromConfig(&arg)
 
 	c.args = append(c.args, &arg)
 	c.argsIdx[arg.name] = &arg
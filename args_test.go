@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -140,3 +141,64 @@ func TestIntsArg(t *testing.T) {
 	b = cmd.Ints(IntsArg{Name: "b", Value: nil, EnvVar: "B C D E F", Desc: ""})
 	require.Equal(t, vi, *b)
 }
+
+func TestFloatArg(t *testing.T) {
+	cmd := &Cmd{argsIdx: map[string]*arg{}}
+	a := cmd.Float(FloatArg{Name: "a", Value: -1.5, Desc: ""})
+	require.Equal(t, -1.5, *a)
+
+	os.Setenv("B", "")
+	b := cmd.Float(FloatArg{Name: "b", Value: -1.5, EnvVar: "B", Desc: ""})
+	require.Equal(t, -1.5, *b)
+
+	os.Setenv("B", "0.25")
+	b = cmd.Float(FloatArg{Name: "b", Value: -1.5, EnvVar: "B", Desc: ""})
+	require.Equal(t, 0.25, *b)
+
+	os.Setenv("B", "abc")
+	b = cmd.Float(FloatArg{Name: "b", Value: -1.5, EnvVar: "B", Desc: ""})
+	require.Equal(t, -1.5, *b)
+}
+
+func TestFloatsArg(t *testing.T) {
+	cmd := &Cmd{argsIdx: map[string]*arg{}}
+
+	vf := []float64{4.2}
+	a := cmd.Floats(FloatsArg{Name: "a", Value: vf, Desc: ""})
+	require.Equal(t, vf, *a)
+
+	os.Setenv("B", "1, 2.5 , 3")
+	b := cmd.Floats(FloatsArg{Name: "b", Value: nil, EnvVar: "B", Desc: ""})
+	require.Equal(t, []float64{1, 2.5, 3}, *b)
+
+	os.Setenv("B", "1, abc")
+	os.Setenv("C", "4.2")
+	b = cmd.Floats(FloatsArg{Name: "b", Value: nil, EnvVar: "B C", Desc: ""})
+	require.Equal(t, vf, *b)
+}
+
+func TestDurationArg(t *testing.T) {
+	cmd := &Cmd{argsIdx: map[string]*arg{}}
+	a := cmd.Duration(DurationArg{Name: "a", Value: time.Second, Desc: ""})
+	require.Equal(t, time.Second, *a)
+
+	os.Setenv("B", "250ms")
+	b := cmd.Duration(DurationArg{Name: "b", Value: time.Second, EnvVar: "B", Desc: ""})
+	require.Equal(t, 250*time.Millisecond, *b)
+
+	os.Setenv("B", "abc")
+	b = cmd.Duration(DurationArg{Name: "b", Value: time.Second, EnvVar: "B", Desc: ""})
+	require.Equal(t, time.Second, *b)
+}
+
+func TestDurationsArg(t *testing.T) {
+	cmd := &Cmd{argsIdx: map[string]*arg{}}
+
+	vd := []time.Duration{time.Second}
+	a := cmd.Durations(DurationsArg{Name: "a", Value: vd, Desc: ""})
+	require.Equal(t, vd, *a)
+
+	os.Setenv("B", "1s, 2m")
+	b := cmd.Durations(DurationsArg{Name: "b", Value: nil, EnvVar: "B", Desc: ""})
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, *b)
+}
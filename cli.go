@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 /*
@@ -38,6 +39,7 @@ func App(name, desc string) *Cli {
 			optionsIdx:    map[string]*opt{},
 			argsIdx:       map[string]*arg{},
 			ErrorHandling: flag.ExitOnError,
+			suggest:       defaultSuggestDistance,
 		},
 	}
 }
@@ -62,6 +64,41 @@ func (cli *Cli) Version(name, version string) {
 	cli.version = &cliVersion{version, option}
 }
 
+/*
+HelpWidth sets the width to which the help messages descriptions are wrapped, with the continuation lines
+indented to the descriptions column.
+
+By default, the help messages are wrapped to the width given by the COLUMNS env variable if set,
+and are not wrapped otherwise (e.g. when the output is not a terminal).
+A negative width disables the wrapping.
+*/
+func (cli *Cli) HelpWidth(width int) {
+	cli.width = width
+}
+
+/*
+Suggestions sets the maximum edit distance between a mistyped command or option and the existing ones
+for the latter to be suggested in the error message, e.g.:
+
+	Error: incorrect usage
+	Did you mean 'status'?
+
+The default distance is 2. A zero or negative distance disables the suggestions.
+*/
+func (cli *Cli) Suggestions(maxDistance int) {
+	cli.suggest = maxDistance
+}
+
+/*
+Abbreviations enables or disables the abbreviation of long options and commands: when enabled, an unambiguous prefix
+of a long option name or of a command alias can be used instead of the full name, e.g. --verb for --verbose.
+
+An ambiguous prefix is reported as an incorrect usage listing the matching names.
+*/
+func (cli *Cli) Abbreviations(enabled bool) {
+	cli.abbrev = enabled
+}
+
 func (cli *Cli) parse(args []string, entry, inFlow, outFlow *step) error {
 	// We overload Cmd.parse() and handle cases that only apply to the CLI command, like versioning
 	// After that, we just call Cmd.parse() for the default behavior
@@ -74,7 +111,14 @@ func (cli *Cli) parse(args []string, entry, inFlow, outFlow *step) error {
 }
 
 func (cli *Cli) versionSetAndRequested(args []string) bool {
-	return cli.version != nil && cli.isFlagSet(args, cli.version.option.names)
+	if cli.version == nil || len(args) == 0 {
+		return false
+	}
+	if cli.abbrev && strings.HasPrefix(args[0], "--") {
+		o, _, _ := lookupLongOpt(cli.optionsIdx, args[0], true)
+		return o == cli.version.option
+	}
+	return cli.isFlagSet(args, cli.version.option.names)
 }
 
 /*
@@ -97,6 +141,10 @@ func (cli *Cli) Run(args []string) error {
 	if err := cli.doInit(); err != nil {
 		panic(err)
 	}
+	if len(args) == 4 && args[1] == completeCmd {
+		cli.printCompletions(args[2], args[3])
+		return nil
+	}
 	inFlow := &step{desc: "RootIn"}
 	outFlow := &step{desc: "RootOut"}
 	return cli.parse(args[1:], inFlow, inFlow, outFlow)
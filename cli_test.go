@@ -2,12 +2,15 @@ package cli
 
 import (
 	"flag"
+	"fmt"
+	"strings"
 
 	"github.com/stretchr/testify/require"
 
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestTheCpCase(t *testing.T) {
@@ -215,6 +218,284 @@ func TestAppWithIntsOption(t *testing.T) {
 	}
 }
 
+func TestAppWithFloatOption(t *testing.T) {
+
+	cases := []struct {
+		args             []string
+		expectedOptValue float64
+	}{
+		{[]string{"app"}, 0.5},
+		{[]string{"app", "-o", "1.25"}, 1.25},
+		{[]string{"app", "-o=-3"}, -3},
+
+		{[]string{"app", "--option", "16"}, 16},
+		{[]string{"app", "--option=1e-2"}, 0.01},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas.args)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		opt := app.FloatOpt("o option", 0.5, "")
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, cas.expectedOptValue, *opt)
+		}
+		err := app.Run(cas.args)
+
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestAppWithFloatsOption(t *testing.T) {
+
+	cases := []struct {
+		args             []string
+		expectedOptValue []float64
+	}{
+		{[]string{"app"}, []float64{1, 2.5}},
+		{[]string{"app", "-o", "0.1"}, []float64{0.1}},
+		{[]string{"app", "-o", "0.1", "-o=11"}, []float64{0.1, 11}},
+
+		{[]string{"app", "--option", "0.1"}, []float64{0.1}},
+		{[]string{"app", "--option", "0.1", "--option=11"}, []float64{0.1, 11}},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas.args)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		opt := app.FloatsOpt("o option", []float64{1, 2.5}, "")
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, cas.expectedOptValue, *opt)
+		}
+		err := app.Run(cas.args)
+
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestAppWithDurationOption(t *testing.T) {
+
+	cases := []struct {
+		args             []string
+		expectedOptValue time.Duration
+	}{
+		{[]string{"app"}, 30 * time.Second},
+		{[]string{"app", "-o", "1m"}, time.Minute},
+		{[]string{"app", "-o=1h30m"}, 90 * time.Minute},
+
+		{[]string{"app", "--option", "10ms"}, 10 * time.Millisecond},
+		{[]string{"app", "--option=2s"}, 2 * time.Second},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas.args)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		opt := app.DurationOpt("o option", 30*time.Second, "")
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, cas.expectedOptValue, *opt)
+		}
+		err := app.Run(cas.args)
+
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestDurationOptionHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.Duration(DurationOpt{Name: "t timeout", Value: 30 * time.Second, Desc: "Timeout"})
+	app.Duration(DurationOpt{Name: "retry", Desc: "Retry interval"})
+
+	app.PrintHelp()
+
+	require.Contains(t, err, "Timeout (default 30s)\n")
+	require.Contains(t, err, "Retry interval\n")
+}
+
+func TestAppWithCountOption(t *testing.T) {
+
+	cases := []struct {
+		spec     string
+		env      string
+		args     []string
+		expected int
+	}{
+		{"", "", []string{"app"}, 0},
+		{"", "", []string{"app", "-v"}, 1},
+		{"", "", []string{"app", "-vvv"}, 3},
+		{"", "", []string{"app", "-v", "-v"}, 2},
+		{"", "", []string{"app", "--verbose", "-vv"}, 3},
+		{"", "", []string{"app", "-xvxv"}, 2},
+		{"", "", []string{"app", "-v=4"}, 4},
+		{"-v...", "", []string{"app", "-vv", "-v"}, 3},
+
+		{"", "2", []string{"app"}, 2},
+		{"", "2", []string{"app", "-v"}, 3},
+		{"", "2", []string{"app", "-v", "-vv"}, 5},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas)
+
+		os.Setenv("MOW_VERBOSITY", cas.env)
+
+		app := App("app", "")
+		app.Spec = cas.spec
+		app.ErrorHandling = flag.ContinueOnError
+		app.BoolOpt("x", false, "")
+		opt := app.Count(CountOpt{Name: "v verbose", EnvVar: "MOW_VERBOSITY"})
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, cas.expected, *opt)
+		}
+		err := app.Run(cas.args)
+
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestAppWithEnumOption(t *testing.T) {
+
+	cases := []struct {
+		args             []string
+		fail             bool
+		expectedOptValue string
+	}{
+		{[]string{"app"}, false, "table"},
+		{[]string{"app", "-f", "json"}, false, "json"},
+		{[]string{"app", "--format=yaml"}, false, "yaml"},
+		{[]string{"app", "-f", "xml"}, true, ""},
+		{[]string{"app", "--format=JSON"}, true, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas.args)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		opt := app.EnumOpt("f format", "table", []string{"json", "yaml", "table"}, "")
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, cas.expectedOptValue, *opt)
+		}
+
+		var stdErr string
+		restore := captureAndRestoreOutput(nil, &stdErr)
+		err := app.Run(cas.args)
+		restore()
+
+		if cas.fail {
+			require.Error(t, err)
+			require.False(t, ex, "Exec shouldn't have been called")
+			require.Contains(t, stdErr, "must be one of json, yaml, table")
+			continue
+		}
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestEnumHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.Enum(EnumOpt{Name: "f format", Value: "table", Choices: []string{"json", "table"}, EnvVar: "FMT", Desc: "Output format"})
+	app.Enums(EnumsArg{Name: "LEVEL", Choices: []string{"info", "warn"}, Desc: "Levels"})
+
+	app.PrintHelp()
+
+	require.Contains(t, err, `Output format (env $FMT) (one of json, table) (default "table")`)
+	require.Contains(t, err, `Levels (one of info, warn)`)
+}
+
+func TestAppWithStringMapOption(t *testing.T) {
+
+	cases := []struct {
+		args             []string
+		expectedOptValue map[string]string
+	}{
+		{[]string{"app"}, map[string]string{"a": "b"}},
+		{[]string{"app", "-l", "x=1"}, map[string]string{"x": "1"}},
+		{[]string{"app", "-l", "x=1", "-l=y=2"}, map[string]string{"x": "1", "y": "2"}},
+		{[]string{"app", "--label", "x=1", "--label=x=2"}, map[string]string{"x": "2"}},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas.args)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		opt := app.StringMapOpt("l label", map[string]string{"a": "b"}, "")
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, cas.expectedOptValue, *opt)
+		}
+		err := app.Run(cas.args)
+
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestAppWithStringMapOptionRejectingDuplicates(t *testing.T) {
+	defer suppressOutput()()
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.StringMap(StringMapOpt{Name: "l label", RejectDuplicateKeys: true})
+
+	ex := false
+	app.Action = func() {
+		ex = true
+	}
+
+	require.NoError(t, app.Run([]string{"app", "-l", "x=1", "-l", "y=1"}))
+	require.True(t, ex, "Exec wasn't called")
+
+	ex = false
+	require.Error(t, app.Run([]string{"app", "-l", "x=1", "-l", "x=2"}))
+	require.False(t, ex, "Exec shouldn't have been called")
+}
+
+func TestStringMapHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.StringMap(StringMapOpt{Name: "l label", Value: map[string]string{"b": "2", "a": "1"}, Desc: "Labels"})
+
+	app.PrintHelp()
+
+	require.Contains(t, err, `Labels (default {"a": "1", "b": "2"})`)
+}
+
 func TestAppWithBoolArg(t *testing.T) {
 
 	cases := []struct {
@@ -499,6 +780,681 @@ func TestLongHelpMessage(t *testing.T) {
 	require.Equal(t, expected, []byte(err))
 }
 
+func TestRequiredOptions(t *testing.T) {
+	defer suppressOutput()()
+
+	cases := []struct {
+		args []string
+		env  string
+		ok   bool
+	}{
+		{[]string{"app", "SRC"}, "", false},
+		{[]string{"app", "--output", "out", "SRC"}, "", true},
+		{[]string{"app", "-v", "--output=out", "SRC"}, "", true},
+		{[]string{"app", "--output", "out", "-v", "SRC"}, "", true},
+		{[]string{"app", "SRC"}, "out", true},
+		{[]string{"app", "--output", "out"}, "", false},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas)
+
+		os.Setenv("MOW_OUTPUT", cas.env)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.BoolOpt("v verbose", false, "")
+		output := app.String(StringOpt{Name: "output", EnvVar: "MOW_OUTPUT", Required: true})
+		app.StringArg("SRC", "", "")
+
+		ex := false
+		app.Action = func() {
+			ex = true
+			require.Equal(t, "out", *output)
+		}
+		err := app.Run(cas.args)
+
+		require.Equal(t, "--output [OPTIONS] SRC ", app.Spec)
+		if !cas.ok {
+			require.Error(t, err)
+			require.False(t, ex, "Exec shouldn't have been called")
+			continue
+		}
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestOnlyRequiredOptions(t *testing.T) {
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.String(StringOpt{Name: "a", Required: true})
+	app.Int(IntOpt{Name: "b", Required: true})
+
+	require.NoError(t, app.doInit())
+	require.Equal(t, "-a -b ", app.Spec)
+}
+
+func TestRequiredOptionsHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.String(StringOpt{Name: "o output", Desc: "Output file", EnvVar: "OUT", Required: true})
+	require.NoError(t, app.doInit())
+
+	app.PrintHelp()
+
+	require.Contains(t, err, "Usage: app -o\n")
+	require.Contains(t, err, "Output file (required) (env $OUT)")
+}
+
+func TestRequiredOptionsHelpWithExplicitSpec(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.Spec = "[-o]"
+	app.String(StringOpt{Name: "o output", Desc: "Output file", Required: true})
+	app.String(StringOpt{Name: "n name", Desc: "Name", Required: true, Persistent: true})
+	app.Command("sub", "", func(cmd *Cmd) {})
+	require.NoError(t, app.doInit())
+
+	app.PrintHelp()
+	require.Contains(t, err, "Usage: app [-o] COMMAND [arg...]\n")
+	require.NotContains(t, err, "(required)")
+
+	err = ""
+	sub := app.commands[0]
+	require.NoError(t, sub.doInit())
+	sub.PrintHelp()
+	require.Contains(t, err, "Name\n")
+	require.NotContains(t, err, "(required)")
+}
+
+func TestValidators(t *testing.T) {
+	positive := func(v int) error {
+		if v <= 0 {
+			return fmt.Errorf("must be positive, got %d", v)
+		}
+		return nil
+	}
+
+	cases := []struct {
+		args  []string
+		env   string
+		error string
+	}{
+		{[]string{"app", "src"}, "", ""},
+		{[]string{"app", "-p", "8080", "src"}, "", ""},
+		{[]string{"app", "--port", "x", "src"}, "", `Error: invalid value "x" for option -p, --port: strconv.ParseInt: parsing "x": invalid syntax`},
+		{[]string{"app", "--port", "0", "src"}, "", "Error: invalid value for option -p, --port: must be positive, got 0"},
+		{[]string{"app", "src"}, "-1", "Error: invalid value for option -p, --port: must be positive, got -1"},
+		{[]string{"app", "-p", "1", "src"}, "-1", ""},
+		{[]string{"app", "SRC"}, "", "Error: invalid value for argument SRC: must be lowercase"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas)
+
+		os.Setenv("MOW_PORT", cas.env)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Int(IntOpt{Name: "p port", EnvVar: "MOW_PORT", Validate: positive})
+		app.String(StringArg{Name: "SRC", Validate: func(v string) error {
+			if strings.ToLower(v) != v {
+				return fmt.Errorf("must be lowercase")
+			}
+			return nil
+		}})
+
+		ex := false
+		app.Action = func() {
+			ex = true
+		}
+
+		var stdErr string
+		restore := captureAndRestoreOutput(nil, &stdErr)
+		err := app.Run(cas.args)
+		restore()
+
+		if cas.error != "" {
+			require.Error(t, err)
+			require.False(t, ex, "Exec shouldn't have been called")
+			require.Contains(t, stdErr, cas.error+"\n")
+			continue
+		}
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestMultiValuedValidator(t *testing.T) {
+	defer suppressOutput()()
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	var validated []string
+	app.Strings(StringsOpt{Name: "e", Validate: func(v []string) error {
+		validated = v
+		return nil
+	}})
+	app.Action = func() {}
+
+	require.NoError(t, app.Run([]string{"app", "-e", "a", "-e", "b"}))
+	require.Equal(t, []string{"a", "b"}, validated)
+}
+
+func TestCommandValidate(t *testing.T) {
+	cases := []struct {
+		args  []string
+		error string
+	}{
+		{[]string{"app", "--since", "1", "--until", "2"}, ""},
+		{[]string{"app", "--since", "3", "--until", "2"}, "--since must be before --until"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas.args)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+
+		since := app.IntOpt("since", 0, "")
+		until := app.IntOpt("until", 0, "")
+
+		validated, before, ex := false, false, false
+		app.Validate = func() error {
+			validated = true
+			require.False(t, before, "Before shouldn't have been called before Validate")
+			if *since > *until {
+				return fmt.Errorf("--since must be before --until")
+			}
+			return nil
+		}
+		app.Before = func() {
+			before = true
+		}
+		app.Action = func() {
+			ex = true
+		}
+
+		var stdErr string
+		restore := captureAndRestoreOutput(nil, &stdErr)
+		err := app.Run(cas.args)
+		restore()
+
+		require.True(t, validated, "Validate wasn't called")
+		if cas.error != "" {
+			require.Error(t, err)
+			require.Equal(t, cas.error, err.Error())
+			require.False(t, before, "Before shouldn't have been called")
+			require.False(t, ex, "Exec shouldn't have been called")
+			require.Contains(t, stdErr, "Error: "+cas.error+"\n")
+			require.Contains(t, stdErr, "Usage: app")
+			continue
+		}
+		require.NoError(t, err)
+		require.True(t, before, "Before wasn't called")
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestCommandValidateExitOnError(t *testing.T) {
+	defer suppressOutput()()
+	exitCalled := false
+	defer exitShouldBeCalledWith(t, 2, &exitCalled)()
+
+	app := App("app", "")
+	app.Command("sub", "", func(cmd *Cmd) {
+		cmd.Validate = func() error {
+			return fmt.Errorf("invalid")
+		}
+		cmd.Action = func() {
+			t.Errorf("Action shouldn't have been called")
+		}
+	})
+
+	app.Run([]string{"app", "sub"})
+
+	require.True(t, exitCalled, "exit should have been called")
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	cases := []struct {
+		args     []string
+		env      map[string]string
+		expected string
+	}{
+		{[]string{"app"}, nil, ""},
+		{[]string{"app", "--endpoint", "x"}, nil, ""},
+		{[]string{"app", "--host", "x"}, nil, "Warning: option --host is deprecated: use --endpoint instead\n"},
+		{[]string{"app"}, map[string]string{"APP_ENDPOINT": "x"}, ""},
+		{[]string{"app"}, map[string]string{"HOST": "x"}, "Warning: env variable $HOST is deprecated: use $APP_ENDPOINT instead\n"},
+		{[]string{"app", "--endpoint", "y"}, map[string]string{"HOST": "x"}, ""},
+		{[]string{"app", "sub", "a"}, nil, "Warning: command sub is deprecated: use new instead\nWarning: argument ARG is deprecated: no longer needed\n"},
+		{[]string{"app", "sub"}, map[string]string{"ARG": "a"}, "Warning: command sub is deprecated: use new instead\nWarning: env variable $ARG is deprecated\n"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v with env %v", cas.args, cas.env)
+		for k, v := range cas.env {
+			os.Setenv(k, v)
+		}
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.String(StringOpt{Name: "endpoint", EnvVar: "APP_ENDPOINT HOST", DeprecatedEnvVars: map[string]string{"HOST": "use $APP_ENDPOINT instead"}})
+		app.String(StringOpt{Name: "host", Deprecated: "use --endpoint instead"})
+		app.Action = func() {}
+		app.Command("sub", "", func(cmd *Cmd) {
+			cmd.Spec = "[ARG]"
+			cmd.String(StringArg{Name: "ARG", EnvVar: "ARG", Deprecated: "no longer needed", DeprecatedEnvVars: map[string]string{"ARG": ""}})
+			cmd.Action = func() {}
+		}, DeprecatedCommand("use new instead"))
+
+		require.NoError(t, app.Run(cas.args))
+		restore()
+		require.Equal(t, cas.expected, err)
+
+		for k := range cas.env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestNegatableBoolOpt(t *testing.T) {
+	cases := []struct {
+		args     []string
+		env      string
+		expected bool
+		err      bool
+	}{
+		{[]string{"app"}, "", true, false},
+		{[]string{"app", "--cache"}, "", true, false},
+		{[]string{"app", "--no-cache"}, "", false, false},
+		{[]string{"app", "--no-cache", "--cache"}, "", true, false},
+		{[]string{"app", "--cache", "--no-cache"}, "", false, false},
+		{[]string{"app", "--no-cached"}, "", false, false},
+		{[]string{"app", "-c"}, "", true, false},
+		{[]string{"app", "--no-cache"}, "true", false, false},
+		{[]string{"app", "--no-cache=false"}, "", false, true},
+		{[]string{"app", "--no-c"}, "", false, true},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v with env %q", cas.args, cas.env)
+		os.Setenv("APP_CACHE", cas.env)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		cache := app.Bool(BoolOpt{Name: "c cache cached", Value: true, EnvVar: "APP_CACHE", Negatable: true})
+		app.Action = func() {}
+
+		runErr := app.Run(cas.args)
+		restore()
+		os.Unsetenv("APP_CACHE")
+
+		if cas.err {
+			require.Error(t, runErr)
+			continue
+		}
+		require.NoError(t, runErr)
+		require.Equal(t, cas.expected, *cache)
+	}
+}
+
+func TestNegatableBoolOptInSpec(t *testing.T) {
+	app := App("app", "")
+	app.Spec = "[--cache] DST"
+	cache := app.Bool(BoolOpt{Name: "cache", Value: true, Negatable: true})
+	app.String(StringArg{Name: "DST"})
+	app.Action = func() {}
+
+	require.NoError(t, app.Run([]string{"app", "--no-cache", "x"}))
+	require.False(t, *cache)
+}
+
+func TestOptionalValue(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected string
+		err      bool
+	}{
+		{[]string{"app"}, "color=auto level=0 mode=fast verbose=false", false},
+		{[]string{"app", "--color"}, "color=always level=0 mode=fast verbose=false", false},
+		{[]string{"app", "--color=never"}, "color=never level=0 mode=fast verbose=false", false},
+		{[]string{"app", "--color", "never"}, "", true},
+		{[]string{"app", "-c"}, "color=always level=0 mode=fast verbose=false", false},
+		{[]string{"app", "-c=never"}, "color=never level=0 mode=fast verbose=false", false},
+		{[]string{"app", "-cv"}, "color=always level=0 mode=fast verbose=true", false},
+		{[]string{"app", "-vc"}, "color=always level=0 mode=fast verbose=true", false},
+		{[]string{"app", "--level", "--color"}, "color=always level=1 mode=fast verbose=false", false},
+		{[]string{"app", "--level=3"}, "color=auto level=3 mode=fast verbose=false", false},
+		{[]string{"app", "--mode"}, "color=auto level=0 mode=safe verbose=false", false},
+		{[]string{"app", "--mode=fast"}, "color=auto level=0 mode=fast verbose=false", false},
+		{[]string{"app", "--mode=slow"}, "", true},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v", cas.args)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		called := ""
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		color := app.String(StringOpt{Name: "c color", Value: "auto", OptionalValue: true, ImplicitValue: "always"})
+		level := app.Int(IntOpt{Name: "level", OptionalValue: true, ImplicitValue: 1})
+		mode := app.Enum(EnumOpt{Name: "mode", Value: "fast", Choices: []string{"fast", "safe"}, OptionalValue: true, ImplicitValue: "safe"})
+		verbose := app.Bool(BoolOpt{Name: "v"})
+		app.Action = func() {
+			called = fmt.Sprintf("color=%s level=%d mode=%s verbose=%v", *color, *level, *mode, *verbose)
+		}
+
+		runErr := app.Run(cas.args)
+		restore()
+
+		if cas.err {
+			require.Error(t, runErr)
+			continue
+		}
+		require.NoError(t, runErr)
+		require.Equal(t, cas.expected, called)
+	}
+}
+
+func TestOptionalValueInvalidImplicitEnum(t *testing.T) {
+	app := App("app", "")
+	require.Panics(t, func() {
+		app.Enum(EnumOpt{Name: "mode", Choices: []string{"fast", "safe"}, OptionalValue: true, ImplicitValue: "slow"})
+	})
+}
+
+func TestPersistentOptions(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"app", "build", "x"}, "build src=x force=false verbose=false env=[]"},
+		{[]string{"app", "-v", "build", "x"}, "build src=x force=false verbose=true env=[]"},
+		{[]string{"app", "build", "-v", "x"}, "build src=x force=false verbose=true env=[]"},
+		{[]string{"app", "build", "-f", "x", "--verbose"}, "build src=x force=true verbose=true env=[]"},
+		{[]string{"app", "build", "-fv", "x"}, "build src=x force=true verbose=true env=[]"},
+		{[]string{"app", "build", "-vf", "x"}, "build src=x force=true verbose=true env=[]"},
+		{[]string{"app", "-e", "a", "build", "x", "-e", "b"}, "build src=x force=false verbose=false env=[a b]"},
+		{[]string{"app", "build", "x", "-e=b"}, "build src=x force=false verbose=false env=[b]"},
+		{[]string{"app", "build", "--", "-v"}, "build src=-v force=false verbose=false env=[]"},
+		{[]string{"app", "image", "ls", "-v"}, "ls verbose=true quiet=false"},
+		{[]string{"app", "image", "-q", "ls", "-v"}, "ls verbose=true quiet=true"},
+		{[]string{"app", "image", "ls", "-q"}, "ls verbose=false quiet=true"},
+		{[]string{"app", "build", "x", "--root"}, ""},
+		{[]string{"app", "image", "ls", "-f"}, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v", cas.args)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		called := ""
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		verbose := app.Bool(BoolOpt{Name: "v verbose", Persistent: true})
+		env := app.Strings(StringsOpt{Name: "e env", Persistent: true})
+		app.Bool(BoolOpt{Name: "root"})
+		app.Command("build", "", func(cmd *Cmd) {
+			cmd.Spec = "[-f] SRC"
+			force := cmd.Bool(BoolOpt{Name: "f force"})
+			src := cmd.String(StringArg{Name: "SRC"})
+			cmd.Action = func() {
+				called = fmt.Sprintf("build src=%s force=%v verbose=%v env=%v", *src, *force, *verbose, *env)
+			}
+		})
+		app.Command("image", "", func(cmd *Cmd) {
+			quiet := cmd.Bool(BoolOpt{Name: "q quiet", Persistent: true})
+			cmd.Command("ls", "", func(cmd *Cmd) {
+				cmd.Action = func() {
+					called = fmt.Sprintf("ls verbose=%v quiet=%v", *verbose, *quiet)
+				}
+			})
+		})
+
+		runErr := app.Run(cas.args)
+		restore()
+
+		require.Equal(t, cas.expected, called)
+		if cas.expected == "" {
+			require.Error(t, runErr)
+		} else {
+			require.NoError(t, runErr)
+		}
+	}
+}
+
+func TestPersistentRequiredOption(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"app", "sub", "-n", "5"}, "sub n=5"},
+		{[]string{"app", "-n", "5", "sub"}, "sub n=5"},
+		{[]string{"app", "-n", "5"}, "app n=5"},
+		{[]string{"app", "sub"}, ""},
+		{[]string{"app"}, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v", cas.args)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		called := ""
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		n := app.Int(IntOpt{Name: "n", Required: true, Persistent: true})
+		app.Action = func() { called = fmt.Sprintf("app n=%d", *n) }
+		app.Command("sub", "", func(cmd *Cmd) {
+			cmd.Action = func() { called = fmt.Sprintf("sub n=%d", *n) }
+		})
+
+		runErr := app.Run(cas.args)
+		restore()
+
+		require.Equal(t, cas.expected, called)
+		if cas.expected == "" {
+			require.Equal(t, errIncorrectUsage, runErr)
+		} else {
+			require.NoError(t, runErr)
+		}
+	}
+}
+
+func TestPersistentRequiredOptionWithExplicitSpec(t *testing.T) {
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.Spec = "[-m]"
+	app.Bool(BoolOpt{Name: "m"})
+	n := app.Int(IntOpt{Name: "n", Required: true, Persistent: true})
+
+	called := false
+	app.Command("sub", "", func(cmd *Cmd) {
+		cmd.Action = func() { called = true }
+	})
+
+	require.NoError(t, app.Run([]string{"app", "sub"}))
+	require.True(t, called)
+	require.Equal(t, 0, *n)
+}
+
+func TestPersistentOptionsParentValidate(t *testing.T) {
+	defer suppressOutput()()
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	n := app.Int(IntOpt{Name: "n", Persistent: true})
+
+	validated := []string{}
+	app.Validate = func() error {
+		validated = append(validated, fmt.Sprintf("app n=%d", *n))
+		if *n > 10 {
+			return fmt.Errorf("-n must be at most 10")
+		}
+		return nil
+	}
+
+	called := false
+	app.Command("sub", "", func(cmd *Cmd) {
+		cmd.Validate = func() error {
+			validated = append(validated, fmt.Sprintf("sub n=%d", *n))
+			return nil
+		}
+		cmd.Action = func() { called = true }
+	})
+
+	require.NoError(t, app.Run([]string{"app", "sub", "-n", "5"}))
+	require.True(t, called)
+	require.Equal(t, []string{"app n=5", "sub n=5"}, validated)
+
+	called = false
+	validated = nil
+	require.EqualError(t, app.Run([]string{"app", "sub", "-n", "11"}), "-n must be at most 10")
+	require.False(t, called)
+	require.Equal(t, []string{"app n=11"}, validated)
+}
+
+func TestPersistentOptionsShadowing(t *testing.T) {
+	app := App("app", "")
+	verbose := app.Bool(BoolOpt{Name: "v verbose", Persistent: true})
+	var version *bool
+	app.Command("sub", "", func(cmd *Cmd) {
+		version = cmd.Bool(BoolOpt{Name: "v version"})
+		cmd.Action = func() {}
+	})
+
+	require.NoError(t, app.Run([]string{"app", "sub", "-v", "--verbose"}))
+	require.True(t, *verbose)
+	require.True(t, *version)
+}
+
+func TestAbbreviations(t *testing.T) {
+	cases := []struct {
+		args     []string
+		enabled  bool
+		expected string
+		err      string
+	}{
+		{[]string{"app", "--verb"}, true, "verbose=true", ""},
+		{[]string{"app", "--verb=false"}, true, "verbose=false", ""},
+		{[]string{"app", "--verbose"}, true, "verbose=true", ""},
+		{[]string{"app", "--nam", "x"}, true, "name=x", ""},
+		{[]string{"app", "--vers"}, true, "", "1.0\n"},
+		{[]string{"app", "--ver"}, true, "", "Error: ambiguous option --ver, could be --verbose, --version\n"},
+		{[]string{"app", "--tr"}, true, "", "Error: incorrect usage\n"},
+		{[]string{"app", "star"}, true, "start", ""},
+		{[]string{"app", "stat"}, true, "status force=false", ""},
+		{[]string{"app", "statu", "--f"}, true, "status force=true", ""},
+		{[]string{"app", "--verb", "stat", "--force"}, true, "status force=true", ""},
+		{[]string{"app", "sta"}, true, "", "Error: ambiguous command sta, could be status, start\n"},
+		{[]string{"app", "--name", "st", "status"}, true, "status force=false", ""},
+		{[]string{"app", "--name", "star", "start"}, true, "start", ""},
+		{[]string{"app", "--name", "star"}, true, "name=star", ""},
+		{[]string{"app", "--name", "x", "star"}, true, "start", ""},
+		{[]string{"app", "in"}, true, "", "Error: incorrect usage\n"},
+		{[]string{"app", "--verb"}, false, "", "Error: incorrect usage\n"},
+		{[]string{"app", "star"}, false, "", "Error: incorrect usage\n"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v with abbreviations %v", cas.args, cas.enabled)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		called := ""
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Abbreviations(cas.enabled)
+		app.Version("version", "1.0")
+		verbose := app.Bool(BoolOpt{Name: "verbose"})
+		name := app.String(StringOpt{Name: "name"})
+		app.Bool(BoolOpt{Name: "trace", Hidden: true})
+		app.Action = func() {
+			called = fmt.Sprintf("verbose=%v", *verbose)
+			if *name != "" {
+				called = fmt.Sprintf("name=%s", *name)
+			}
+		}
+		app.Command("status stat", "", func(cmd *Cmd) {
+			force := cmd.Bool(BoolOpt{Name: "force"})
+			cmd.Action = func() { called = fmt.Sprintf("status force=%v", *force) }
+		})
+		app.Command("start", "", func(cmd *Cmd) {
+			cmd.Action = func() { called = "start" }
+		})
+		app.Command("internal", "", func(cmd *Cmd) {
+			cmd.Action = func() { called = "internal" }
+		}, HiddenCommand())
+
+		app.Run(cas.args)
+		restore()
+
+		require.Equal(t, cas.expected, called)
+		if cas.err == "" {
+			require.Equal(t, "", err)
+		} else {
+			require.True(t, strings.HasPrefix(err, cas.err), "unexpected output %q", err)
+		}
+	}
+}
+
+func TestAbbreviationsDoubleDash(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+		err      string
+	}{
+		{[]string{"app", "--", "-x"}, []string{"-x"}, ""},
+		{[]string{"app", "--=x"}, nil, "Error: incorrect usage\n"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v", cas.args)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		var called []string
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Abbreviations(true)
+		app.Version("version", "1.0")
+		args := app.StringsArg("ARGS", nil, "")
+		app.Spec = "[ARGS...]"
+		app.Action = func() { called = *args }
+
+		app.Run(cas.args)
+		restore()
+
+		require.Equal(t, cas.expected, called)
+		if cas.err == "" {
+			require.Equal(t, "", err)
+		} else {
+			require.True(t, strings.HasPrefix(err, cas.err), "unexpected output %q", err)
+		}
+	}
+}
+
 func TestVersionShortcut(t *testing.T) {
 	defer suppressOutput()()
 	exitCalled := false
@@ -3,8 +3,9 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"strconv"
 	"strings"
-	"text/tabwriter"
+	"time"
 )
 
 /*
@@ -19,25 +20,42 @@ type Cmd struct {
 	Before func()
 	// The code to execute after this command or any of its children is matched
 	After func()
+	// The code to execute once this command's options and arguments are parsed, before the Before and Action code, to validate them.
+	// For a command with sub commands, it is called once the sub command to run was parsed too, so that the persistent options
+	// given after its name are set. A non nil error is treated as an incorrect usage
+	Validate func() error
 	// The command options and arguments
 	Spec string
 	// The command long description to be shown when help is requested
 	LongDesc string
 	// The command error handling strategy
 	ErrorHandling flag.ErrorHandling
+	// The renderer of the command help messages. Inherited from the parent command when nil
+	HelpRenderer HelpRenderer
 
 	init    CmdInitializer
 	name    string
 	aliases []string
 	desc    string
-
-	commands   []*Cmd
-	options    []*opt
-	optionsIdx map[string]*opt
-	args       []*arg
-	argsIdx    map[string]*arg
-
+	hidden  bool
+	// the deprecation message, see DeprecatedCommand
+	deprecated string
+	// whether Spec was generated from the options and arguments, see autoSpec
+	specGenerated bool
+
+	commands      []*Cmd
+	options       []*opt
+	optionsIdx    map[string]*opt
+	globalOptions []*opt
+	args          []*arg
+	argsIdx       map[string]*arg
+
+	parent  *Cmd
 	parents []string
+	config  ConfigSource
+	width   int
+	suggest int
+	abbrev  bool
 
 	fsm *state
 }
@@ -47,6 +65,7 @@ BoolParam represents a Bool option or argument
 */
 type BoolParam interface {
 	value() bool
+	validate(bool) error
 }
 
 /*
@@ -54,6 +73,7 @@ StringParam represents a String option or argument
 */
 type StringParam interface {
 	value() string
+	validate(string) error
 }
 
 /*
@@ -61,6 +81,7 @@ IntParam represents an Int option or argument
 */
 type IntParam interface {
 	value() int
+	validate(int) error
 }
 
 /*
@@ -68,6 +89,7 @@ StringsParam represents a string slice option or argument
 */
 type StringsParam interface {
 	value() []string
+	validate([]string) error
 }
 
 /*
@@ -75,6 +97,66 @@ IntsParam represents an int slice option or argument
 */
 type IntsParam interface {
 	value() []int
+	validate([]int) error
+}
+
+/*
+FloatParam represents a float64 option or argument
+*/
+type FloatParam interface {
+	value() float64
+	validate(float64) error
+}
+
+/*
+FloatsParam represents a float64 slice option or argument
+*/
+type FloatsParam interface {
+	value() []float64
+	validate([]float64) error
+}
+
+/*
+DurationParam represents a time.Duration option or argument
+*/
+type DurationParam interface {
+	value() time.Duration
+	validate(time.Duration) error
+}
+
+/*
+DurationsParam represents a time.Duration slice option or argument
+*/
+type DurationsParam interface {
+	value() []time.Duration
+	validate([]time.Duration) error
+}
+
+/*
+EnumParam represents an enum (a string restricted to a set of choices) option or argument
+*/
+type EnumParam interface {
+	value() string
+	choices() []string
+	validate(string) error
+}
+
+/*
+EnumsParam represents an enum slice option or argument
+*/
+type EnumsParam interface {
+	value() []string
+	choices() []string
+	validate([]string) error
+}
+
+/*
+StringMapParam represents a string map option or argument
+*/
+type StringMapParam interface {
+	value() map[string]string
+	rejectDuplicateKeys() bool
+	validate(map[string]string) error
 }
 
 /*
@@ -82,6 +164,7 @@ VarParam represents an custom option or argument where the type and format are c
 */
 type VarParam interface {
 	value() flag.Value
+	validate(flag.Value) error
 }
 
 /*
@@ -90,6 +173,36 @@ to execute when the command is called
 */
 type CmdInitializer func(*Cmd)
 
+/*
+CommandOption configures a command when it is declared, i.e. before its init function is called,
+for the settings which are needed to list the command without initializing it
+*/
+type CommandOption func(*Cmd)
+
+/*
+HiddenCommand hides the command from its parent help message, the generated documentation and the shell completion.
+It can still be called:
+
+	app.Command("debug", "Internal debugging commands", initDebug, cli.HiddenCommand())
+*/
+func HiddenCommand() CommandOption {
+	return func(c *Cmd) {
+		c.hidden = true
+	}
+}
+
+/*
+DeprecatedCommand marks the command as deprecated with a message, e.g. "use image build instead".
+The command is marked as such in its parent help message, and a warning is printed when it is called:
+
+	app.Command("rmi", "Remove images", initRmi, cli.DeprecatedCommand("use image rm instead"))
+*/
+func DeprecatedCommand(msg string) CommandOption {
+	return func(c *Cmd) {
+		c.deprecated = msg
+	}
+}
+
 /*
 Command adds a new (sub) command to c where name is the command name (what you type in the console),
 description is what would be shown in the help messages, e.g.:
@@ -99,12 +212,15 @@ description is what would be shown in the help messages, e.g.:
 	Commands:
 	  $name	$desc
 
-the last argument, init, is a function that will be called by mow.cli to further configure the created
-(sub) command, e.g. to add options, arguments and the code to execute
+the init argument is a function that will be called by mow.cli to further configure the created
+(sub) command, e.g. to add options, arguments and the code to execute.
+It is only called when the command is selected, or when its whole documentation is generated.
+
+The optional options configure the command settings which are needed before its init function is called, e.g. HiddenCommand()
 */
-func (c *Cmd) Command(name, desc string, init CmdInitializer) {
+func (c *Cmd) Command(name, desc string, init CmdInitializer, options ...CommandOption) {
 	aliases := strings.Fields(name)
-	c.commands = append(c.commands, &Cmd{
+	cmd := &Cmd{
 		ErrorHandling: c.ErrorHandling,
 		name:          aliases[0],
 		aliases:       aliases,
@@ -115,7 +231,11 @@ func (c *Cmd) Command(name, desc string, init CmdInitializer) {
 		optionsIdx:    map[string]*opt{},
 		args:          []*arg{},
 		argsIdx:       map[string]*arg{},
-	})
+	}
+	for _, option := range options {
+		option(cmd)
+	}
+	c.commands = append(c.commands, cmd)
 }
 
 /*
@@ -127,12 +247,13 @@ The result should be stored in a variable (a pointer to a bool) which will be po
 func (c *Cmd) Bool(p BoolParam) *bool {
 	into := new(bool)
 	value := newBoolValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
 
 	switch x := p.(type) {
 	case BoolOpt:
-		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, negatable: x.Negatable, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate})
 	case BoolArg:
-		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	default:
 		panic(fmt.Sprintf("Unhandled param %v", p))
 	}
@@ -149,12 +270,13 @@ The result should be stored in a variable (a pointer to a string) which will be
 func (c *Cmd) String(p StringParam) *string {
 	into := new(string)
 	value := newStringValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
 
 	switch x := p.(type) {
 	case StringOpt:
-		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, optionalValue: x.OptionalValue, implicitValue: x.ImplicitValue, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	case StringArg:
-		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	default:
 		panic(fmt.Sprintf("Unhandled param %v", p))
 	}
@@ -171,12 +293,13 @@ The result should be stored in a variable (a pointer to an int) which will be po
 func (c *Cmd) Int(p IntParam) *int {
 	into := new(int)
 	value := newIntValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
 
 	switch x := p.(type) {
 	case IntOpt:
-		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, optionalValue: x.OptionalValue, implicitValue: strconv.Itoa(x.ImplicitValue), hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	case IntArg:
-		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	default:
 		panic(fmt.Sprintf("Unhandled param %v", p))
 	}
@@ -193,12 +316,13 @@ The result should be stored in a variable (a pointer to a string slice) which wi
 func (c *Cmd) Strings(p StringsParam) *[]string {
 	into := new([]string)
 	value := newStringsValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
 
 	switch x := p.(type) {
 	case StringsOpt:
-		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	case StringsArg:
-		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	default:
 		panic(fmt.Sprintf("Unhandled param %v", p))
 	}
@@ -215,12 +339,211 @@ The result should be stored in a variable (a pointer to an int slice) which will
 func (c *Cmd) Ints(p IntsParam) *[]int {
 	into := new([]int)
 	value := newIntsValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
 
 	switch x := p.(type) {
 	case IntsOpt:
-		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	case IntsArg:
-		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: value, valueSetByUser: x.SetByUser})
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+Float can be used to add a float64 option or argument to a command.
+It accepts either a FloatOpt or a FloatArg struct.
+
+The result should be stored in a variable (a pointer to a float64) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Float(p FloatParam) *float64 {
+	into := new(float64)
+	value := newFloatValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case FloatOpt:
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case FloatArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+Floats can be used to add a float64 slice option or argument to a command.
+It accepts either a FloatsOpt or a FloatsArg struct.
+
+The result should be stored in a variable (a pointer to a float64 slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Floats(p FloatsParam) *[]float64 {
+	into := new([]float64)
+	value := newFloatsValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case FloatsOpt:
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case FloatsArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+Duration can be used to add a time.Duration option or argument to a command.
+It accepts either a DurationOpt or a DurationArg struct.
+
+The result should be stored in a variable (a pointer to a time.Duration) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Duration(p DurationParam) *time.Duration {
+	into := new(time.Duration)
+	value := newDurationValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case DurationOpt:
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case DurationArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+Durations can be used to add a time.Duration slice option or argument to a command.
+It accepts either a DurationsOpt or a DurationsArg struct.
+
+The result should be stored in a variable (a pointer to a time.Duration slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Durations(p DurationsParam) *[]time.Duration {
+	into := new([]time.Duration)
+	value := newDurationsValue(into, p.value())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case DurationsOpt:
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case DurationsArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+Count can be used to add a counter option to a command.
+
+The result should be stored in a variable (a pointer to an int) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Count(o CountOpt) *int {
+	into := new(int)
+	value := newCountValue(into, o.Value)
+
+	validate := func() error { return o.validate(*into) }
+
+	c.mkOpt(opt{name: o.Name, desc: o.Desc, envVar: o.EnvVar, required: o.Required, hideValue: o.HideValue, group: o.Group, hidden: o.Hidden, persistent: o.Persistent, deprecated: o.Deprecated, deprecatedEnvVars: o.DeprecatedEnvVars, value: value, valueSetByUser: o.SetByUser, validate: validate})
+
+	return into
+}
+
+/*
+Enum can be used to add a string option or argument which only accepts a predefined set of values to a command.
+It accepts either an EnumOpt or an EnumArg struct.
+
+Values not in the choices list are rejected when parsing the call arguments.
+
+The result should be stored in a variable (a pointer to a string) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Enum(p EnumParam) *string {
+	if p.value() != "" {
+		if err := checkChoice(p.value(), p.choices()); err != nil {
+			panic(fmt.Sprintf("Invalid initial value: %v", err))
+		}
+	}
+
+	into := new(string)
+	value := newEnumValue(into, p.value(), p.choices())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case EnumOpt:
+		if x.OptionalValue {
+			if err := checkChoice(x.ImplicitValue, x.Choices); err != nil {
+				panic(fmt.Sprintf("Invalid implicit value: %v", err))
+			}
+		}
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, optionalValue: x.OptionalValue, implicitValue: x.ImplicitValue, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case EnumArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+Enums can be used to add a string slice option or argument which only accepts a predefined set of values to a command.
+It accepts either an EnumsOpt or an EnumsArg struct.
+
+Values not in the choices list are rejected when parsing the call arguments.
+
+The result should be stored in a variable (a pointer to a string slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) Enums(p EnumsParam) *[]string {
+	for _, v := range p.value() {
+		if err := checkChoice(v, p.choices()); err != nil {
+			panic(fmt.Sprintf("Invalid initial value: %v", err))
+		}
+	}
+
+	into := new([]string)
+	value := newEnumsValue(into, p.value(), p.choices())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case EnumsOpt:
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case EnumsArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	default:
+		panic(fmt.Sprintf("Unhandled param %v", p))
+	}
+
+	return into
+}
+
+/*
+StringMap can be used to add a string map option or argument to a command.
+It accepts either a StringMapOpt or a StringMapArg struct.
+
+The result should be stored in a variable (a pointer to a string map) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) StringMap(p StringMapParam) *map[string]string {
+	into := new(map[string]string)
+	value := newStringMapValue(into, p.value(), p.rejectDuplicateKeys())
+	validate := func() error { return p.validate(*into) }
+
+	switch x := p.(type) {
+	case StringMapOpt:
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
+	case StringMapArg:
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: value, valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	default:
 		panic(fmt.Sprintf("Unhandled param %v", p))
 	}
@@ -236,34 +559,55 @@ As opposed to the other built-in types, this function does not return a pointer
 Instead, the VarOpt or VarOptArg structs hold the said value.
 */
 func (c *Cmd) Var(p VarParam) {
+	validate := func() error { return p.validate(p.value()) }
+
 	switch x := p.(type) {
 	case VarOpt:
-		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: p.value(), valueSetByUser: x.SetByUser})
+		c.mkOpt(opt{name: x.Name, desc: x.Desc, envVar: x.EnvVar, required: x.Required, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, persistent: x.Persistent, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: p.value(), valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	case VarArg:
-		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, value: p.value(), valueSetByUser: x.SetByUser})
+		c.mkArg(arg{name: x.Name, desc: x.Desc, envVar: x.EnvVar, hideValue: x.HideValue, group: x.Group, hidden: x.Hidden, deprecated: x.Deprecated, deprecatedEnvVars: x.DeprecatedEnvVars, value: p.value(), valueSetByUser: x.SetByUser, validate: validate, complete: x.Complete})
 	default:
 		panic(fmt.Sprintf("Unhandled param %v", p))
 	}
 }
 
 func (c *Cmd) doInit() error {
+	// already initialized, e.g. by the completion or the documentation generators
+	if c.fsm != nil {
+		return nil
+	}
+
 	if c.init != nil {
 		c.init(c)
 	}
 
-	parents := append(c.parents, c.name)
+	c.registerGlobalOptions()
+
+	parents := c.path()
+
+	globals := append([]*opt{}, c.globalOptions...)
+	for _, opt := range c.options {
+		if opt.persistent {
+			globals = append(globals, opt)
+		}
+	}
 
 	for _, sub := range c.commands {
+		sub.parent = c
 		sub.parents = parents
+		sub.globalOptions = globals
+		sub.config = c.config
+		sub.width = c.width
+		sub.suggest = c.suggest
+		sub.abbrev = c.abbrev
+		if sub.HelpRenderer == nil {
+			sub.HelpRenderer = c.HelpRenderer
+		}
 	}
 
 	if len(c.Spec) == 0 {
-		if len(c.options) > 0 {
-			c.Spec = "[OPTIONS] "
-		}
-		for _, arg := range c.args {
-			c.Spec += arg.name + " "
-		}
+		c.Spec = c.autoSpec(false)
+		c.specGenerated = true
 	}
 	fsm, err := uParse(c)
 	if err != nil {
@@ -273,6 +617,58 @@ func (c *Cmd) doInit() error {
 	return nil
 }
 
+// autoSpec generates the spec of a command declared without one: its required options, [OPTIONS] if it has
+// optional ones, then its arguments. The hidden options and arguments are left out when visibleOnly is set
+func (c *Cmd) autoSpec(visibleOnly bool) string {
+	spec := ""
+	optional := false
+	for _, opt := range c.options {
+		switch {
+		case visibleOnly && opt.hidden:
+		// a persistent option can also be given after a sub command name, see validateChain
+		case opt.required && !opt.persistent:
+			spec += opt.names[0] + " "
+		default:
+			optional = true
+		}
+	}
+	if optional {
+		spec += "[OPTIONS] "
+	}
+	for _, arg := range c.args {
+		if visibleOnly && arg.hidden {
+			continue
+		}
+		spec += arg.name + " "
+	}
+	return spec
+}
+
+// registerGlobalOptions indexes the persistent options inherited from the parent commands under their names
+// which are not used by the command own options, the closest parent winning, and drops the completely shadowed ones
+func (c *Cmd) registerGlobalOptions() {
+	globals := []*opt{}
+	for i := len(c.globalOptions) - 1; i >= 0; i-- {
+		o := c.globalOptions[i]
+		registered := false
+		for _, name := range append(append([]string{}, o.names...), o.negatedNames...) {
+			if _, taken := c.optionsIdx[name]; !taken {
+				c.optionsIdx[name] = o
+				registered = true
+			}
+		}
+		if registered {
+			globals = append([]*opt{o}, globals...)
+		}
+	}
+	c.globalOptions = globals
+}
+
+// path returns the app name followed by the sub commands names leading to this command
+func (c *Cmd) path() []string {
+	return append(append([]string{}, c.parents...), c.name)
+}
+
 func (c *Cmd) onError(err error) {
 	if err == errHelpRequested || err == errVersionRequested {
 		if c.ErrorHandling == flag.ExitOnError {
@@ -309,74 +705,20 @@ func (c *Cmd) PrintLongHelp() {
 }
 
 func (c *Cmd) printHelp(longDesc bool) {
-	full := append(c.parents, c.name)
-	path := strings.Join(full, " ")
-	fmt.Fprintf(stdErr, "\nUsage: %s", path)
-
-	spec := strings.TrimSpace(c.Spec)
-	if len(spec) > 0 {
-		fmt.Fprintf(stdErr, " %s", spec)
-	}
-
-	if len(c.commands) > 0 {
-		fmt.Fprint(stdErr, " COMMAND [arg...]")
-	}
-	fmt.Fprint(stdErr, "\n\n")
-
-	desc := c.desc
-	if longDesc && len(c.LongDesc) > 0 {
-		desc = c.LongDesc
-	}
-	if len(desc) > 0 {
-		fmt.Fprintf(stdErr, "%s\n", desc)
+	renderer := c.HelpRenderer
+	if renderer == nil {
+		renderer = defaultHelpRenderer{}
 	}
 
-	w := tabwriter.NewWriter(stdErr, 15, 1, 3, ' ', 0)
-
-	if len(c.args) > 0 {
-		fmt.Fprint(w, "\t\nArguments:\t\n")
-
-		for _, arg := range c.args {
-			var (
-				env   = formatEnvVarsForHelp(arg.envVar)
-				value = formatValueForHelp(arg.hideValue, arg.value)
-			)
-			fmt.Fprintf(w, "  %s\t%s\n", arg.name, joinStrings(arg.desc, env, value))
-		}
-	}
-
-	if len(c.options) > 0 {
-		fmt.Fprint(w, "\t\nOptions:\t\n")
-
-		for _, opt := range c.options {
-			var (
-				optNames = formatOptNamesForHelp(opt)
-				env      = formatEnvVarsForHelp(opt.envVar)
-				value    = formatValueForHelp(opt.hideValue, opt.value)
-			)
-			fmt.Fprintf(w, "  %s\t%s\n", optNames, joinStrings(opt.desc, env, value))
-		}
+	if err := renderer.RenderHelp(stdErr, c.help(longDesc)); err != nil {
+		fmt.Fprintf(stdErr, "Error: failed to render the help message: %s\n", err.Error())
 	}
-
-	if len(c.commands) > 0 {
-		fmt.Fprint(w, "\t\nCommands:\t\n")
-
-		for _, c := range c.commands {
-			fmt.Fprintf(w, "  %s\t%s\n", strings.Join(c.aliases, ", "), c.desc)
-		}
-	}
-
-	if len(c.commands) > 0 {
-		fmt.Fprintf(w, "\t\nRun '%s COMMAND --help' for more information on a command.\n", path)
-	}
-
-	w.Flush()
 }
 
-func formatOptNamesForHelp(o *opt) string {
-	short, long := "", ""
-
-	for _, n := range o.names {
+// optShortAndLongNames returns the first short name (e.g. -f) and the first long name (e.g. --force) of an option,
+// or an empty string if it has none
+func optShortAndLongNames(names []string) (short, long string) {
+	for _, n := range names {
 		if len(n) == 2 && short == "" {
 			short = n
 		}
@@ -385,6 +727,11 @@ func formatOptNamesForHelp(o *opt) string {
 			long = n
 		}
 	}
+	return
+}
+
+func formatOptNamesForHelp(names []string) string {
+	short, long := optShortAndLongNames(names)
 
 	switch {
 	case short != "" && long != "":
@@ -399,7 +746,16 @@ func formatOptNamesForHelp(o *opt) string {
 	}
 }
 
-func formatValueForHelp(hide bool, v flag.Value) string {
+func formatValueForHelp(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("(default %s)", value)
+}
+
+// helpValue returns the current value to display in the help, or an empty string if it is hidden or a default one
+func helpValue(hide bool, v flag.Value) string {
 	if hide {
 		return ""
 	}
@@ -410,14 +766,47 @@ func formatValueForHelp(hide bool, v flag.Value) string {
 		}
 	}
 
-	return fmt.Sprintf("(default %s)", v.String())
+	return v.String()
+}
+
+func formatRequiredForHelp(required bool) string {
+	if !required {
+		return ""
+	}
+	return "(required)"
+}
+
+func formatChoicesForHelp(choices []string) string {
+	if len(choices) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("(one of %s)", strings.Join(choices, ", "))
+}
+
+func formatDeprecatedForHelp(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return fmt.Sprintf("(deprecated: %s)", msg)
+}
+
+func formatDeprecatedEnvVarForHelp(envVar string, deprecated map[string]string) string {
+	msg, found := deprecated[envVar]
+	switch {
+	case !found:
+		return ""
+	case msg == "":
+		return "(deprecated)"
+	default:
+		return formatDeprecatedForHelp(msg)
+	}
 }
 
-func formatEnvVarsForHelp(envVars string) string {
-	if strings.TrimSpace(envVars) == "" {
+func formatEnvVarsForHelp(vars []string, deprecated map[string]string) string {
+	if len(vars) == 0 {
 		return ""
 	}
-	vars := strings.Fields(envVars)
 	res := "(env"
 	sep := " "
 	for i, v := range vars {
@@ -425,6 +814,9 @@ func formatEnvVarsForHelp(envVars string) string {
 			sep = ", "
 		}
 		res += fmt.Sprintf("%s$%s", sep, v)
+		if _, found := deprecated[v]; found {
+			res += " (deprecated)"
+		}
 	}
 	res += ")"
 	return res
@@ -437,10 +829,29 @@ func (c *Cmd) parse(args []string, entry, inFlow, outFlow *step) error {
 		return nil
 	}
 
+	if c.deprecated != "" {
+		warnDeprecated("command "+c.name, c.deprecated)
+	}
+
 	nargsLen := c.getOptsAndArgs(args)
 
-	if err := c.fsm.parse(args[:nargsLen]); err != nil {
+	cmdArgs, err := c.parseGlobalOptions(args[:nargsLen])
+	if err == nil {
+		err = c.fsm.parse(cmdArgs)
+	}
+	if err == nil && nargsLen == len(args) {
+		err = c.validateChain()
+	}
+	unexpected := ""
+	if err == errIncorrectUsage {
+		unexpected = c.unexpectedArg(cmdArgs)
+		err = c.ambiguityError(unexpected, err)
+	}
+	if err != nil {
 		fmt.Fprintf(stdErr, "Error: %s\n", err.Error())
+		if err == errIncorrectUsage {
+			c.printSuggestions(unexpected)
+		}
 		c.PrintHelp()
 		c.onError(err)
 		return err
@@ -479,16 +890,13 @@ func (c *Cmd) parse(args []string, entry, inFlow, outFlow *step) error {
 	}
 
 	arg := args[0]
-	for _, sub := range c.commands {
-		if sub.isAlias(arg) {
-			if err := sub.doInit(); err != nil {
-				panic(err)
-			}
-			return sub.parse(args[1:], entry, newInFlow, newOutFlow)
+	if sub := c.subCommand(arg); sub != nil {
+		if err := sub.doInit(); err != nil {
+			panic(err)
 		}
+		return sub.parse(args[1:], entry, newInFlow, newOutFlow)
 	}
 
-	var err error
 	switch {
 	case strings.HasPrefix(arg, "-"):
 		err = fmt.Errorf("Error: illegal option %s", arg)
@@ -497,12 +905,40 @@ func (c *Cmd) parse(args []string, entry, inFlow, outFlow *step) error {
 		err = fmt.Errorf("Error: illegal input %s", arg)
 		fmt.Fprintln(stdErr, err.Error())
 	}
+	c.printSuggestions(arg)
 	c.PrintHelp()
 	c.onError(err)
 	return err
 
 }
 
+// validateChain checks the required persistent options and calls the Validate hooks of the command and of its parents,
+// root first, once the command which will be run got all the call arguments
+func (c *Cmd) validateChain() error {
+	chain := []*Cmd{}
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		chain = append([]*Cmd{cmd}, chain...)
+	}
+
+	for _, cmd := range chain {
+		for _, opt := range cmd.options {
+			if opt.persistent && cmd.requiredOption(opt) && opt.valueSource == ValueSourceDefault {
+				return errIncorrectUsage
+			}
+		}
+	}
+
+	for _, cmd := range chain {
+		if cmd.Validate == nil {
+			continue
+		}
+		if err := cmd.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Cmd) helpRequested(args []string) bool {
 	return c.isFlagSet(args, []string{"-h", "--help"})
 }
@@ -524,17 +960,136 @@ func (c *Cmd) isFlagSet(args []string, searchArgs []string) bool {
 func (c *Cmd) getOptsAndArgs(args []string) int {
 	consumed := 0
 
-	for _, arg := range args {
-		for _, sub := range c.commands {
-			if sub.isAlias(arg) {
-				return consumed
-			}
+	for i, arg := range args {
+		if c.exactSubCommand(arg) != nil {
+			return consumed
+		}
+		if c.abbreviatedSubCommand(arg) != nil && c.inCommandPosition(args[:i]) {
+			return consumed
 		}
 		consumed++
 	}
 	return consumed
 }
 
+// inCommandPosition returns true if the word following args can only be a sub command, i.e. if args are a complete call
+// of the command after which its spec cannot consume another argument
+func (c *Cmd) inCommandPosition(args []string) bool {
+	args, _ = c.extractGlobalOptions(args)
+	if ok, _ := c.fsm.apply(args, newParseContext()); !ok {
+		return false
+	}
+	return len(c.fsm.expectedArgs(args)) == 0
+}
+
+// subCommand returns the sub command with the alias name, or, when abbreviations are enabled,
+// the only visible sub command with an alias starting with name
+func (c *Cmd) subCommand(name string) *Cmd {
+	if sub := c.exactSubCommand(name); sub != nil {
+		return sub
+	}
+	return c.abbreviatedSubCommand(name)
+}
+
+func (c *Cmd) exactSubCommand(name string) *Cmd {
+	for _, sub := range c.commands {
+		if sub.isAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// abbreviatedSubCommand returns, when abbreviations are enabled, the only visible sub command with an alias starting with prefix
+func (c *Cmd) abbreviatedSubCommand(prefix string) *Cmd {
+	if !c.abbrev || prefix == "" {
+		return nil
+	}
+	var res *Cmd
+	for _, sub := range c.visibleCommands() {
+		if sub.aliasWithPrefix(prefix) != "" {
+			if res != nil {
+				return nil
+			}
+			res = sub
+		}
+	}
+	return res
+}
+
+// subCommandCandidates returns, for each visible sub command with an alias starting with prefix, the first such alias
+func (c *Cmd) subCommandCandidates(prefix string) []string {
+	res := []string{}
+	for _, sub := range c.visibleCommands() {
+		if alias := sub.aliasWithPrefix(prefix); alias != "" {
+			res = append(res, alias)
+		}
+	}
+	return res
+}
+
+func (c *Cmd) aliasWithPrefix(prefix string) string {
+	for _, alias := range c.aliases {
+		if strings.HasPrefix(alias, prefix) {
+			return alias
+		}
+	}
+	return ""
+}
+
+// parseGlobalOptions sets the persistent options inherited from the parent commands found anywhere in args
+// (before a --), and returns the remaining args for the command own spec
+func (c *Cmd) parseGlobalOptions(args []string) ([]string, error) {
+	args, pc := c.extractGlobalOptions(args)
+
+	for _, opt := range c.globalOptions {
+		vs, found := pc.opts[opt]
+		if !found {
+			continue
+		}
+		if err := opt.setFromCommandLine(vs, true); err != nil {
+			return nil, err
+		}
+		if opt.validate == nil {
+			continue
+		}
+		if err := opt.validate(); err != nil {
+			return nil, fmt.Errorf("invalid value for option %s: %s", strings.Join(opt.names, ", "), err.Error())
+		}
+	}
+	return args, nil
+}
+
+// extractGlobalOptions removes the persistent options inherited from the parent commands from args (before a --),
+// returning the remaining args and the removed options values
+func (c *Cmd) extractGlobalOptions(args []string) ([]string, parseContext) {
+	pc := newParseContext()
+	if len(c.globalOptions) == 0 {
+		return args, pc
+	}
+
+	for i := 0; i < len(args) && args[i] != "--"; {
+		if !strings.HasPrefix(args[i], "-") || args[i] == "-" {
+			i++
+			continue
+		}
+
+		matched := false
+		for _, opt := range c.globalOptions {
+			found := len(pc.opts[opt])
+			if ok, nargs := (&optMatcher{theOne: opt, optionsIdx: c.optionsIdx, abbrev: c.abbrev}).match(args[i:], &pc); ok && len(pc.opts[opt]) > found {
+				args = append(append([]string{}, args[:i]...), nargs...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return args, pc
+}
+
 func (c *Cmd) isAlias(arg string) bool {
 	for _, alias := range c.aliases {
 		if arg == alias {
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// completeCmd is the hidden first argument which makes Run print the completion candidates
+// instead of executing the app: app __complete SHELL LINE
+const completeCmd = "__complete"
+
+var bashCompletionTemplate = template.Must(template.New("bash").Parse(`# bash completion for {{.Name}}
+# source this file or copy it to /etc/bash_completion.d/{{.Name}}
+
+{{.Func}}() {
+	local IFS=$'\n'
+	COMPREPLY=($("${COMP_WORDS[0]}" ` + completeCmd + ` bash "${COMP_LINE:0:$COMP_POINT}" 2>/dev/null))
+}
+
+complete -o default -F {{.Func}} {{.Name}}
+`))
+
+/*
+WriteBashCompletion writes a bash completion script for the app to w.
+
+The script calls back the app binary to compute the completion candidates, so that they always match the commands,
+options and arguments of the installed version:
+
+	app.Command("completion", "Print the bash completion script", func(cmd *cli.Cmd) {
+		cmd.Action = func() {
+			app.WriteBashCompletion(os.Stdout)
+		}
+	})
+
+And then, in a shell:
+
+	source <(app completion)
+*/
+func (cli *Cli) WriteBashCompletion(w io.Writer) error {
+	return bashCompletionTemplate.Execute(w, map[string]string{
+		"Name": cli.name,
+		"Func": completionFuncName(cli.name),
+	})
+}
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func completionFuncName(name string) string {
+	return "_" + nonIdentChars.ReplaceAllString(name, "_") + "_completion"
+}
+
+func (cli *Cli) printCompletions(shell, line string) {
+	for _, c := range cli.complete(shell, line) {
+		fmt.Fprintln(stdOut, c)
+	}
+}
+
+// complete returns the completion candidates for the last word of line, a command line typed up to the cursor position
+func (cli *Cli) complete(shell, line string) []string {
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.TrimRight(line, " \t") != line {
+		words = append(words, "")
+	}
+	if len(words) < 2 {
+		return nil
+	}
+	words = words[1:]
+	cur := words[len(words)-1]
+
+	cmd := cli.Cmd
+	var pending *opt
+	rejectOptions := false
+	// the words typed after the current command name
+	typed := []string{}
+	for _, w := range words[:len(words)-1] {
+		typed = append(typed, w)
+		switch {
+		case pending != nil:
+			pending = nil
+		case w == "--":
+			rejectOptions = true
+		case !rejectOptions && strings.HasPrefix(w, "-") && w != "-":
+			pending = cmd.optionExpectingValue(w)
+		default:
+			// like when parsing, an abbreviated command name is only resolved where no argument is expected
+			sub := cmd.exactSubCommand(w)
+			if sub == nil && cmd.inCommandPosition(typed[:len(typed)-1]) {
+				sub = cmd.abbreviatedSubCommand(w)
+			}
+			if sub != nil {
+				if err := sub.doInit(); err != nil {
+					return nil
+				}
+				cmd = sub
+				rejectOptions = false
+				typed = []string{}
+			}
+		}
+	}
+
+	switch {
+	case pending != nil:
+		return optionValues(pending, cur)
+	case rejectOptions || !strings.HasPrefix(cur, "-"):
+		res := filterByPrefix(cmd.subCommandNames(), cur)
+		for _, a := range cmd.fsm.expectedArgs(typed) {
+			if a.hidden {
+				continue
+			}
+			res = append(res, argValues(a, cur)...)
+		}
+		return res
+	case strings.HasPrefix(cur, "--") && strings.Contains(cur, "="):
+		kv := strings.SplitN(cur, "=", 2)
+		o, found := cmd.optionsIdx[kv[0]]
+		if !found {
+			return nil
+		}
+		res := optionValues(o, kv[1])
+		if shell != "bash" {
+			// bash treats = as a word separator and only replaces the text following it
+			for i, v := range res {
+				res[i] = kv[0] + "=" + v
+			}
+		}
+		return res
+	default:
+		return filterByPrefix(cmd.optionNames(), cur)
+	}
+}
+
+// optionExpectingValue returns the option in w if the next word is expected to be its value, nil otherwise
+func (c *Cmd) optionExpectingValue(w string) *opt {
+	if strings.Contains(w, "=") {
+		return nil
+	}
+
+	name := w
+	if !strings.HasPrefix(w, "--") {
+		// in a short options cluster, only the last one may take its value from the next word
+		name = "-" + w[len(w)-1:]
+		for i := 1; i < len(w)-1; i++ {
+			if o, found := c.optionsIdx["-"+w[i:i+1]]; !found {
+				return nil
+			} else if _, bare := o.bareValue(); !bare {
+				return nil
+			}
+		}
+	}
+
+	o, _, found := lookupLongOpt(c.optionsIdx, name, c.abbrev && strings.HasPrefix(name, "--"))
+	if !found {
+		return nil
+	}
+	if _, bare := o.bareValue(); bare {
+		return nil
+	}
+	return o
+}
+
+func (c *Cmd) subCommandNames() []string {
+	res := []string{}
+	for _, sub := range c.visibleCommands() {
+		res = append(res, sub.aliases...)
+	}
+	return res
+}
+
+func (c *Cmd) optionNames() []string {
+	res := []string{}
+	for name, o := range c.optionsIdx {
+		if o.hidden {
+			continue
+		}
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// optionValues returns the completion candidates for the value of an option starting with prefix:
+// those returned by its Complete callback if any, otherwise its choices for enum options
+func optionValues(o *opt, prefix string) []string {
+	switch {
+	case o.complete != nil:
+		return filterByPrefix(o.complete(prefix), prefix)
+	case o.isBool():
+		return filterByPrefix([]string{"true", "false"}, prefix)
+	default:
+		return valueChoices(o.value, prefix)
+	}
+}
+
+func argValues(a *arg, prefix string) []string {
+	if a.complete != nil {
+		return filterByPrefix(a.complete(prefix), prefix)
+	}
+	return valueChoices(a.value, prefix)
+}
+
+func valueChoices(v interface{}, prefix string) []string {
+	if cv, ok := v.(choicesValued); ok {
+		return filterByPrefix(cv.Choices(), prefix)
+	}
+	return []string{}
+}
+
+// hasArgCompleter tells whether one of the visible arguments of the command has a Complete function
+func (c *Cmd) hasArgCompleter() bool {
+	for _, a := range c.args {
+		if !a.hidden && a.complete != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func filterByPrefix(candidates []string, prefix string) []string {
+	res := []string{}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+WriteFishCompletion writes a fish completion script for the app to w.
+
+Unlike the bash one, the script is generated statically from the commands tree,
+and shows the options and commands descriptions next to the candidates.
+It only calls back the app binary for the values of the options and arguments with a Complete function:
+
+	app completion fish | source
+*/
+func (cli *Cli) WriteFishCompletion(w io.Writer) error {
+	var buf bytes.Buffer
+	fn := "__" + nonIdentChars.ReplaceAllString(cli.name, "_")
+
+	var aliases, paths []string
+	if err := collectFishCommands(cli.Cmd, "", &aliases, &paths); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&buf, "# fish completion for %s\n\n", cli.name)
+	fmt.Fprintf(&buf, "function %s_command_path\n", fn)
+	fmt.Fprintf(&buf, "\tset -l aliases %s\n", fishQuoteAll(aliases))
+	fmt.Fprintf(&buf, "\tset -l commands %s\n", fishQuoteAll(paths))
+	fmt.Fprintf(&buf, "\tset -l path ''\n\tset -l words (commandline -opc)\n\tset -e words[1]\n")
+	fmt.Fprintf(&buf, "\tfor w in $words\n\t\tset -l i (contains -i -- (string trim -- \"$path $w\") $aliases)\n\t\tand set path $commands[$i]\n\tend\n")
+	fmt.Fprintf(&buf, "\techo $path\nend\n\n")
+	fmt.Fprintf(&buf, "function %s_at\n\tset -l path (%s_command_path)\n\ttest \"$path\" = \"$argv\"\nend\n\n", fn, fn)
+	fmt.Fprintf(&buf, "function %s_values\n\tset -l words (commandline -opc)\n\t$words[1] %s fish (commandline -cp) 2>/dev/null\nend\n", fn, completeCmd)
+
+	writeFishCmdCompletion(&buf, cli.name, fn, "", cli.Cmd)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// collectFishCommands lists the commands paths as typed (with aliases) and their canonical forms
+func collectFishCommands(c *Cmd, path string, aliases, paths *[]string) error {
+	for _, sub := range c.visibleCommands() {
+		if err := sub.doInit(); err != nil {
+			return err
+		}
+		subPath := strings.TrimSpace(path + " " + sub.name)
+		for _, alias := range sub.aliases {
+			*aliases = append(*aliases, strings.TrimSpace(path+" "+alias))
+			*paths = append(*paths, subPath)
+		}
+		if err := collectFishCommands(sub, subPath, aliases, paths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFishCmdCompletion(w io.Writer, name, fn, path string, c *Cmd) {
+	cond := fmt.Sprintf("-n %s", fishQuote(fmt.Sprintf("%s_at %s", fn, fishQuote(path))))
+	values := fishQuote("(" + fn + "_values)")
+
+	fmt.Fprintf(w, "\n")
+	for _, sub := range c.visibleCommands() {
+		for _, alias := range sub.aliases {
+			fmt.Fprintf(w, "complete -c %s %s -f -a %s%s\n", name, cond, fishQuote(alias), fishDesc(sub.desc))
+		}
+	}
+	for _, o := range append(append([]*opt{}, c.options...), c.globalOptions...) {
+		if o.hidden {
+			continue
+		}
+		short, long := optShortAndLongNames(o.names)
+		line := fmt.Sprintf("complete -c %s %s", name, cond)
+		if short != "" {
+			line += " -s " + short[1:]
+		}
+		if long != "" {
+			line += " -l " + long[2:]
+		}
+		if !o.isBool() {
+			if !o.optionalValue {
+				line += " -r"
+			}
+			if o.complete != nil {
+				line += " -f -a " + values
+			} else if cv, ok := o.value.(choicesValued); ok {
+				line += " -f -a " + fishQuote(strings.Join(cv.Choices(), " "))
+			}
+		}
+		fmt.Fprintf(w, "%s%s\n", line, fishDesc(o.desc))
+		for _, n := range o.negatedNames {
+			fmt.Fprintf(w, "complete -c %s %s -l %s%s\n", name, cond, n[2:], fishDesc(o.desc))
+		}
+	}
+	if c.hasArgCompleter() {
+		fmt.Fprintf(w, "complete -c %s %s -a %s\n", name, cond, values)
+	}
+
+	for _, sub := range c.visibleCommands() {
+		writeFishCmdCompletion(w, name, fn, strings.TrimSpace(path+" "+sub.name), sub)
+	}
+}
+
+func fishDesc(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	return " -d " + fishQuote(desc)
+}
+
+var fishEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+func fishQuote(s string) string {
+	return "'" + fishEscaper.Replace(s) + "'"
+}
+
+func fishQuoteAll(ss []string) string {
+	res := make([]string, len(ss))
+	for i, s := range ss {
+		res[i] = fishQuote(s)
+	}
+	return strings.Join(res, " ")
+}
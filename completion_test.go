@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func completionTestApp() *Cli {
+	app := App("app", "")
+	app.BoolOpt("v verbose", false, "")
+	app.Bool(BoolOpt{Name: "trace", Hidden: true})
+	app.Command("debug", "", func(cmd *Cmd) {}, HiddenCommand())
+	app.Command("build b", "", func(cmd *Cmd) {
+		cmd.Enum(EnumOpt{Name: "f format", Value: "json", Choices: []string{"json", "yaml", "text"}})
+		cmd.StringOpt("o output", "", "")
+		cmd.BoolOpt("q", false, "")
+		cmd.StringArg("SRC", "", "")
+	})
+	app.Command("deploy", "", func(cmd *Cmd) {
+		cmd.Command("start", "", func(cmd *Cmd) {})
+		cmd.Command("stop", "", func(cmd *Cmd) {})
+	})
+	return app
+}
+
+func completionCallbacksCmd(cmd *Cmd) {
+	cmd.String(StringOpt{Name: "remote", Complete: func(string) []string { return []string{"origin"} }})
+	cmd.String(StringArg{Name: "BRANCH", Complete: func(string) []string { return []string{"main"} }})
+}
+
+func TestComplete(t *testing.T) {
+	cases := []struct {
+		line     string
+		shell    string
+		expected []string
+	}{
+		{"app ", "bash", []string{"build", "b", "deploy"}},
+		{"app d", "bash", []string{"deploy"}},
+		{"app -", "bash", []string{"--verbose", "-v"}},
+		{"app -v deploy ", "bash", []string{"start", "stop"}},
+		{"app b --", "bash", []string{"--format", "--output"}},
+		{"app build -f ", "bash", []string{"json", "yaml", "text"}},
+		{"app build -qf y", "bash", []string{"yaml"}},
+		{"app build --format=j", "bash", []string{"json"}},
+		{"app build --format=j", "fish", []string{"--format=json"}},
+		{"app build -o ", "bash", []string{}},
+		{"app build -o deploy ", "bash", []string{}},
+		{"app build -- -", "bash", []string{}},
+		{"app build --nope=", "bash", nil},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %q", cas.line)
+		app := completionTestApp()
+		require.Nil(t, app.doInit())
+		require.Equal(t, cas.expected, app.complete(cas.shell, cas.line))
+	}
+}
+
+func TestCompleteAbbreviations(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected []string
+	}{
+		{"app bu -", []string{"--verbose", "-v"}},
+		{"app x bu -", []string{"--force", "-f"}},
+		{"app x build -", []string{"--force", "-f"}},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %q", cas.line)
+		app := App("app", "")
+		app.Abbreviations(true)
+		app.Spec = "[-v] [SRC]"
+		app.BoolOpt("v verbose", false, "")
+		app.StringArg("SRC", "", "")
+		app.Command("build", "", func(cmd *Cmd) {
+			cmd.BoolOpt("f force", false, "")
+		})
+		require.Nil(t, app.doInit())
+		require.Equal(t, cas.expected, app.complete("bash", cas.line))
+	}
+}
+
+func TestCompletionEntryPoint(t *testing.T) {
+	var out string
+	defer captureAndRestoreOutput(&out, nil)()
+	defer exitShouldNotCalled(t)()
+
+	app := completionTestApp()
+	app.Action = func() {
+		t.Errorf("action should not have been called")
+	}
+
+	require.Nil(t, app.Run([]string{"app", completeCmd, "bash", "app build --format "}))
+	require.Equal(t, "json\nyaml\ntext\n", out)
+}
+
+func TestWriteBashCompletion(t *testing.T) {
+	var out bytes.Buffer
+	app := App("my-app", "")
+	require.Nil(t, app.WriteBashCompletion(&out))
+
+	script := out.String()
+	require.Contains(t, script, "_my_app_completion() {")
+	require.Contains(t, script, `"${COMP_WORDS[0]}" __complete bash "${COMP_LINE:0:$COMP_POINT}"`)
+	require.Contains(t, script, "complete -o default -F _my_app_completion my-app")
+}
+
+func TestWriteZshCompletion(t *testing.T) {
+	var out bytes.Buffer
+	app := completionTestApp()
+	require.Nil(t, app.WriteZshCompletion(&out))
+
+	script := out.String()
+	require.Contains(t, script, "#compdef app\n")
+	require.Contains(t, script, "\n_app() {\n")
+	require.Contains(t, script, `'(-v --verbose)'{-v,--verbose}`)
+	require.Contains(t, script, `'(-f --format)'{-f,--format}':format:(json yaml text)'`)
+	require.Contains(t, script, `'(-q)'-q \`)
+	require.Contains(t, script, "\t\t\t'b:'\n")
+	require.Contains(t, script, "\t\tbuild|b)\n\t\t\t_app_build\n")
+	require.Contains(t, script, "\n_app_deploy_start() {\n")
+	require.Contains(t, script, "\n_app_deploy_stop() {\n\tlocal curcontext=\"$curcontext\" state line\n\ttypeset -A opt_args\n\n\t_message 'no more arguments'\n}\n")
+	require.Contains(t, script, "compdef _app app")
+	require.Contains(t, script, "'*: :_default'\n")
+
+	out.Reset()
+	app = completionTestApp()
+	app.Command("checkout", "", completionCallbacksCmd)
+	require.Nil(t, app.WriteZshCompletion(&out))
+
+	script = out.String()
+	require.Contains(t, script, "\n__app_values() {\n")
+	require.Contains(t, script, `__complete zsh "${BUFFER[1,CURSOR]}"`)
+	require.Contains(t, script, `'(--remote)'--remote':remote:__app_values'`)
+	require.Contains(t, script, "'*: :__app_values'\n")
+}
+
+func TestZshOptSpec(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	cmd.StringsOpt("e env", nil, "Set [env] var: K=V")
+	cmd.BoolOpt("force", false, "Don't ask")
+
+	require.Equal(t, `'*'{-e,--env}'[Set \[env\] var\: K=V]:env:_default'`, zshOptSpec(cmd.options[0], "__app_values"))
+	require.Equal(t, `'(--force)'--force'[Don'\''t ask]'`, zshOptSpec(cmd.options[1], "__app_values"))
+
+	cmd.Bool(BoolOpt{Name: "c cache", Desc: "Use the cache", Negatable: true})
+	require.Equal(t, `'(-c --cache --no-cache)'{-c,--cache}'[Use the cache]'`, zshOptSpec(cmd.options[2], "__app_values"))
+	require.Equal(t, `'(-c --cache --no-cache)'--no-cache'[Use the cache]'`, zshNegatedOptSpec(cmd.options[2], "--no-cache"))
+
+	cmd.Enum(EnumOpt{Name: "color", Choices: []string{"always", "never"}, OptionalValue: true, ImplicitValue: "always"})
+	require.Equal(t, `'(--color)'--color=-'::color:(always never)'`, zshOptSpec(cmd.options[3], "__app_values"))
+}
+
+func TestWriteFishCompletion(t *testing.T) {
+	var out bytes.Buffer
+	app := completionTestApp()
+	app.Cmd.desc = "It's an app"
+	require.Nil(t, app.WriteFishCompletion(&out))
+
+	script := out.String()
+	require.Contains(t, script, "\tset -l aliases 'build' 'b' 'deploy' 'deploy start' 'deploy stop'\n")
+	require.Contains(t, script, "\tset -l commands 'build' 'build' 'deploy' 'deploy start' 'deploy stop'\n")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'\\'' -f -a 'b'\n")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'\\'' -s v -l verbose\n")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'build\\'' -s f -l format -r -f -a 'json yaml text'\n")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'build\\'' -s o -l output -r\n")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'deploy\\'' -f -a 'start'\n")
+	require.NotContains(t, script, "-a '(__app_values)'")
+
+	out.Reset()
+	app = completionTestApp()
+	app.Command("checkout", "", completionCallbacksCmd)
+	require.Nil(t, app.WriteFishCompletion(&out))
+
+	script = out.String()
+	require.Contains(t, script, "function __app_values\n")
+	require.Contains(t, script, "$words[1] __complete fish (commandline -cp)")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'checkout\\'' -l remote -r -f -a '(__app_values)'\n")
+	require.Contains(t, script, "complete -c app -n '__app_at \\'checkout\\'' -a '(__app_values)'\n")
+}
+
+func TestCompleteCallbacks(t *testing.T) {
+	prefixes := []string{}
+	complete := func(values ...string) func(string) []string {
+		return func(prefix string) []string {
+			prefixes = append(prefixes, prefix)
+			return values
+		}
+	}
+
+	app := App("git", "")
+	app.Command("checkout", "", func(cmd *Cmd) {
+		cmd.Spec = "[-b=<NEW>] [--remote=<REMOTE>] BRANCH [MODE] [FILES...]"
+		cmd.String(StringOpt{Name: "b", Complete: complete("new")})
+		cmd.String(StringOpt{Name: "remote", Complete: complete("origin", "upstream")})
+		cmd.String(StringArg{Name: "BRANCH", Complete: complete("master", "main")})
+		cmd.Enum(EnumArg{Name: "MODE", Value: "soft", Choices: []string{"soft", "hard"}})
+		cmd.Strings(StringsArg{Name: "FILES", Complete: complete("a.go", "b.go")})
+	})
+	require.Nil(t, app.doInit())
+
+	cases := []struct {
+		line     string
+		expected []string
+		prefixes []string
+	}{
+		{"git checkout ", []string{"master", "main"}, []string{""}},
+		{"git checkout ma", []string{"master", "main"}, []string{"ma"}},
+		{"git checkout mai", []string{"main"}, []string{"mai"}},
+		{"git checkout -b x ", []string{"master", "main"}, []string{""}},
+		{"git checkout -b ", []string{"new"}, []string{""}},
+		{"git checkout --remote=up", []string{"upstream"}, []string{"up"}},
+		{"git checkout --remote o", []string{"origin"}, []string{"o"}},
+		{"git checkout main ", []string{"soft", "hard", "a.go", "b.go"}, []string{""}},
+		{"git checkout main h", []string{"hard"}, []string{"h"}},
+		{"git checkout main b", []string{"b.go"}, []string{"b"}},
+		{"git checkout main hard a.go ", []string{"a.go", "b.go"}, []string{""}},
+		{"git checkout main -- -", []string{}, []string{"-"}},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %q", cas.line)
+		prefixes = []string{}
+		require.Equal(t, cas.expected, app.complete("bash", cas.line))
+		require.Equal(t, cas.prefixes, prefixes)
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+WriteZshCompletion writes a zsh completion script for the app to w.
+
+Unlike the bash one, the script is generated statically from the commands tree,
+and shows the options and commands descriptions next to the candidates.
+It only calls back the app binary for the values of the options and arguments with a Complete function:
+
+	source <(app completion zsh)
+*/
+func (cli *Cli) WriteZshCompletion(w io.Writer) error {
+	var buf bytes.Buffer
+	fn := "_" + nonIdentChars.ReplaceAllString(cli.name, "_")
+
+	values := "_" + fn + "_values"
+
+	fmt.Fprintf(&buf, "#compdef %s\n", cli.name)
+	fmt.Fprintf(&buf, "\n%s() {\n\tlocal -a values\n", values)
+	fmt.Fprintf(&buf, "\tvalues=(${(f)\"$(${(Q)${(z)BUFFER}[1]} %s zsh \"${BUFFER[1,CURSOR]}\" 2>/dev/null)\"})\n", completeCmd)
+	fmt.Fprintf(&buf, "\tif (( ${#values} )); then\n\t\tcompadd -a values\n\telse\n\t\t_default\n\tfi\n}\n")
+	if err := writeZshCmdCompletion(&buf, fn, values, cli.Cmd); err != nil {
+		return err
+	}
+	fmt.Fprintf(&buf, "\nif [ \"$funcstack[1]\" = \"%s\" ]; then\n\t%s \"$@\"\nelse\n\tcompdef %s %s\nfi\n", fn, fn, fn, cli.name)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeZshCmdCompletion writes the fn function completing the command c,
+// values being the function which calls back the app for the values with a Complete function
+func writeZshCmdCompletion(w io.Writer, fn, values string, c *Cmd) error {
+	if err := c.doInit(); err != nil {
+		return err
+	}
+
+	opts := []*opt{}
+	for _, o := range append(append([]*opt{}, c.options...), c.globalOptions...) {
+		if !o.hidden {
+			opts = append(opts, o)
+		}
+	}
+	commands := c.visibleCommands()
+
+	fmt.Fprintf(w, "\n%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal curcontext=\"$curcontext\" state line\n\ttypeset -A opt_args\n\n")
+	if len(opts) == 0 && len(commands) == 0 && len(c.args) == 0 {
+		fmt.Fprintf(w, "\t_message 'no more arguments'\n}\n")
+		return nil
+	}
+
+	fmt.Fprintf(w, "\t_arguments -C")
+	for _, o := range opts {
+		fmt.Fprintf(w, " \\\n\t\t%s", zshOptSpec(o, values))
+		for _, n := range o.negatedNames {
+			fmt.Fprintf(w, " \\\n\t\t%s", zshNegatedOptSpec(o, n))
+		}
+	}
+	switch {
+	case len(commands) > 0:
+		fmt.Fprintf(w, " \\\n\t\t'1: :->cmds' \\\n\t\t'*:: :->args'\n")
+	case len(c.args) > 0:
+		action := "_default"
+		if c.hasArgCompleter() {
+			action = values
+		}
+		fmt.Fprintf(w, " \\\n\t\t'*: :%s'\n", action)
+	default:
+		fmt.Fprintf(w, "\n")
+	}
+
+	if len(commands) > 0 {
+		fmt.Fprintf(w, "\n\tcase $state in\n\tcmds)\n\t\tlocal -a commands\n\t\tcommands=(\n")
+		for _, sub := range commands {
+			for _, alias := range sub.aliases {
+				fmt.Fprintf(w, "\t\t\t%s\n", zshQuote(zshEscape(alias)+":"+sub.desc))
+			}
+		}
+		fmt.Fprintf(w, "\t\t)\n\t\t_describe -t commands 'command' commands\n\t\t;;\n\targs)\n\t\tcase $line[1] in\n")
+		for _, sub := range commands {
+			fmt.Fprintf(w, "\t\t%s)\n\t\t\t%s_%s\n\t\t\t;;\n", strings.Join(sub.aliases, "|"), fn, nonIdentChars.ReplaceAllString(sub.name, "_"))
+		}
+		fmt.Fprintf(w, "\t\tesac\n\t\t;;\n\tesac\n")
+	}
+	fmt.Fprintf(w, "}\n")
+
+	for _, sub := range commands {
+		if err := writeZshCmdCompletion(w, fn+"_"+nonIdentChars.ReplaceAllString(sub.name, "_"), values, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zshOptSpec formats an option as an _arguments spec, e.g. '(-f --format)'{-f,--format}'[Output format]:format:(json text)',
+// the values of an option with a Complete function being completed by the values function
+func zshOptSpec(o *opt, values string) string {
+	short, long := optShortAndLongNames(o.names)
+	names := strings.TrimSpace(short + " " + long)
+
+	res := "'(" + strings.TrimSpace(names+" "+strings.Join(o.negatedNames, " ")) + ")'"
+	if _, multi := o.value.(multiValued); multi {
+		res = "'*'"
+	}
+
+	if o.optionalValue && long != "" {
+		// the optional value can only follow the equal sign, in the same word
+		long += "=-"
+	}
+	if short != "" && long != "" {
+		res += "{" + short + "," + long + "}"
+	} else {
+		res += strings.TrimSpace(short + " " + long)
+	}
+
+	spec := ""
+	if o.desc != "" {
+		spec = "[" + zshEscape(o.desc) + "]"
+	}
+	if !o.isBool() {
+		action := "_default"
+		if o.complete != nil {
+			action = values
+		} else if cv, ok := o.value.(choicesValued); ok {
+			action = "(" + strings.Join(cv.Choices(), " ") + ")"
+		}
+		if o.optionalValue {
+			spec += ":"
+		}
+		spec += ":" + zshEscape(o.configName()) + ":" + action
+	}
+	if spec != "" {
+		res += zshQuote(spec)
+	}
+	return res
+}
+
+// zshNegatedOptSpec formats the --no-<name> form of a negatable option as an _arguments spec,
+// excluding the option itself, e.g. '(-c --cache --no-cache)--no-cache[Use the cache]'
+func zshNegatedOptSpec(o *opt, negated string) string {
+	short, long := optShortAndLongNames(o.names)
+	res := "'(" + strings.TrimSpace(short+" "+long+" "+negated) + ")'" + negated
+	if o.desc != "" {
+		res += zshQuote("[" + zshEscape(o.desc) + "]")
+	}
+	return res
+}
+
+var zshEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`)
+
+func zshEscape(s string) string {
+	return zshEscaper.Replace(s)
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
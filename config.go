@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+ConfigSource provides initial values for options and arguments, e.g. from a configuration file.
+
+Lookup is called with the path of a command (the app name followed by the sub commands names, e.g. ["docker", "run"])
+and the name of one of its options (the first long name without the dashes, or the short name if the option has no long name)
+or arguments.
+It should return the raw values to set, which will be parsed like command line values, and whether a value was found.
+Multi-valued options and arguments receive all the returned values, the others only the last one.
+*/
+type ConfigSource interface {
+	Lookup(cmdPath []string, name string) ([]string, bool)
+}
+
+/*
+Config sets the source used to initialize the options and arguments of the app and all its commands.
+
+Values are applied with the following precedence (highest first): command line, env variables, config source, initial value.
+Invalid config values are ignored, with a warning when the command is run.
+*/
+func (cli *Cli) Config(source ConfigSource) {
+	cli.config = source
+
+	// the options and arguments declared before the source was set
+	for _, opt := range cli.options {
+		cli.setOptFromConfig(opt)
+	}
+	for _, arg := range cli.args {
+		cli.setArgFromConfig(arg)
+	}
+}
+
+type jsonConfig map[string]map[string]interface{}
+
+/*
+JSONConfig creates a ConfigSource from a JSON document read from r.
+
+The document is an object whose keys are the commands paths (the app name followed by the sub commands names, separated by spaces),
+each mapping to an object keyed by the options long names (or short names if an option has no long name) and the arguments names:
+
+	{
+		"docker": {"debug": true},
+		"docker run": {"memory": "1g", "env": ["A=1", "B=2"], "label": {"team": "ops"}}
+	}
+
+Arrays provide multiple values and objects provide key=value pairs, e.g. for string map options.
+*/
+func JSONConfig(r io.Reader) (ConfigSource, error) {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	var res jsonConfig
+	if err := d.Decode(&res); err != nil {
+		return nil, fmt.Errorf("invalid JSON config: %s", err.Error())
+	}
+	return res, nil
+}
+
+/*
+JSONConfigFile creates a ConfigSource from the JSON file at path.
+See JSONConfig for the expected format.
+*/
+func JSONConfigFile(path string) (ConfigSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return JSONConfig(f)
+}
+
+func (jc jsonConfig) Lookup(cmdPath []string, name string) ([]string, bool) {
+	values, found := jc[strings.Join(cmdPath, " ")][name]
+	if !found || values == nil {
+		return nil, false
+	}
+
+	switch x := values.(type) {
+	case []interface{}:
+		res := []string{}
+		for _, v := range x {
+			res = append(res, fmt.Sprintf("%v", v))
+		}
+		return res, true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		res := []string{}
+		for _, k := range keys {
+			res = append(res, fmt.Sprintf("%s=%v", k, x[k]))
+		}
+		return res, true
+	default:
+		return []string{fmt.Sprintf("%v", x)}, true
+	}
+}
+
+// setOptFromConfig sets an option which was not set from the env from the config source,
+// keeping the error of an invalid value to warn about it when the command is run
+func (c *Cmd) setOptFromConfig(o *opt) {
+	if o.valueSource != ValueSourceDefault {
+		return
+	}
+
+	found, err := setFromConfig(o.value, c.config, c.path(), o.configName())
+	if found {
+		o.valueSource = ValueSourceConfig
+	}
+	o.configErr = err
+}
+
+// setArgFromConfig is the setOptFromConfig counterpart for arguments
+func (c *Cmd) setArgFromConfig(a *arg) {
+	if a.valueSource != ValueSourceDefault {
+		return
+	}
+
+	found, err := setFromConfig(a.value, c.config, c.path(), a.name)
+	if found {
+		a.valueSource = ValueSourceConfig
+	}
+	a.configErr = err
+}
+
+func setFromConfig(into flag.Value, source ConfigSource, cmdPath []string, name string) (bool, error) {
+	if source == nil {
+		return false, nil
+	}
+
+	vs, found := source.Lookup(cmdPath, name)
+	if !found || len(vs) == 0 {
+		return false, nil
+	}
+
+	if multiValued, isMulti := into.(multiValued); isMulti {
+		if err := setMultivalued(multiValued, vs); err != nil {
+			return false, fmt.Errorf("invalid value %q: %s", strings.Join(vs, ","), err.Error())
+		}
+		return true, nil
+	}
+
+	v := vs[len(vs)-1]
+	if err := into.Set(v); err != nil {
+		return false, fmt.Errorf("invalid value %q: %s", v, err.Error())
+	}
+	return true, nil
+}
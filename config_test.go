@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONConfigLookup(t *testing.T) {
+	source, err := JSONConfig(strings.NewReader(`{
+		"app": {"str": "a", "int": 42, "float": 0.5, "bool": true, "null": null},
+		"app sub": {"strs": ["x", "y"], "ints": [1, 2], "map": {"b": "2", "a": 1}}
+	}`))
+	require.NoError(t, err)
+
+	cases := []struct {
+		path     []string
+		name     string
+		found    bool
+		expected []string
+	}{
+		{[]string{"app"}, "str", true, []string{"a"}},
+		{[]string{"app"}, "int", true, []string{"42"}},
+		{[]string{"app"}, "float", true, []string{"0.5"}},
+		{[]string{"app"}, "bool", true, []string{"true"}},
+		{[]string{"app"}, "null", false, nil},
+		{[]string{"app"}, "strs", false, nil},
+		{[]string{"app", "sub"}, "strs", true, []string{"x", "y"}},
+		{[]string{"app", "sub"}, "ints", true, []string{"1", "2"}},
+		{[]string{"app", "sub"}, "map", true, []string{"a=1", "b=2"}},
+		{[]string{"app", "other"}, "str", false, nil},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas)
+
+		values, found := source.Lookup(cas.path, cas.name)
+		require.Equal(t, cas.found, found)
+		require.Equal(t, cas.expected, values)
+	}
+}
+
+func TestJSONConfigInvalid(t *testing.T) {
+	_, err := JSONConfig(strings.NewReader(`{"app": `))
+	require.Error(t, err)
+
+	_, err = JSONConfig(strings.NewReader(`{"app": 42}`))
+	require.Error(t, err)
+
+	_, err = JSONConfigFile("testdata/does-not-exist.json")
+	require.Error(t, err)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	cases := []struct {
+		args     []string
+		env      string
+		expected string
+	}{
+		{[]string{"app", "sub"}, "", "config"},
+		{[]string{"app", "sub"}, "env", "env"},
+		{[]string{"app", "sub", "--name", "cli"}, "env", "cli"},
+		{[]string{"app", "sub", "--name", "cli"}, "", "cli"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas)
+
+		os.Setenv("MOW_NAME", cas.env)
+
+		source, err := JSONConfig(strings.NewReader(`{
+			"app": {"v": true},
+			"app sub": {"name": "config", "n": 3, "tags": ["a", "b"], "ARG": "arg"}
+		}`))
+		require.NoError(t, err)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Config(source)
+		verbose := app.BoolOpt("v", false, "")
+
+		ex := false
+		app.Command("sub", "", func(cmd *Cmd) {
+			name := cmd.String(StringOpt{Name: "name", Value: "default", EnvVar: "MOW_NAME"})
+			n := cmd.Int(IntOpt{Name: "n", Required: true})
+			tags := cmd.StringsOpt("t tags", nil, "")
+			other := cmd.StringOpt("other", "default", "")
+			a := cmd.String(StringArg{Name: "ARG"})
+			cmd.Spec = "[OPTIONS] [ARG]"
+
+			cmd.Action = func() {
+				ex = true
+				require.True(t, *verbose)
+				require.Equal(t, cas.expected, *name)
+				require.Equal(t, 3, *n)
+				require.Equal(t, []string{"a", "b"}, *tags)
+				require.Equal(t, "default", *other)
+				require.Equal(t, "arg", *a)
+			}
+		})
+
+		require.NoError(t, app.Run(cas.args))
+		require.True(t, ex, "Exec wasn't called")
+	}
+}
+
+func TestConfigSatisfiesRequiredOption(t *testing.T) {
+	source, err := JSONConfig(strings.NewReader(`{"app": {"output": "out"}}`))
+	require.NoError(t, err)
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.Config(source)
+	output := app.String(StringOpt{Name: "o output", Required: true})
+
+	ex := false
+	app.Action = func() {
+		ex = true
+		require.Equal(t, "out", *output)
+	}
+
+	require.NoError(t, app.Run([]string{"app"}))
+	require.True(t, ex, "Exec wasn't called")
+}
+
+func TestConfigSetBeforeOrAfterOptions(t *testing.T) {
+	source, err := JSONConfig(strings.NewReader(`{"app": {"before": "b", "after": "a", "ARG": "arg"}}`))
+	require.NoError(t, err)
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	before := app.StringOpt("before", "", "")
+	a := app.StringArg("ARG", "", "")
+	app.Config(source)
+	after := app.StringOpt("after", "", "")
+	app.Spec = "[OPTIONS] [ARG]"
+
+	ex := false
+	app.Action = func() {
+		ex = true
+		require.Equal(t, "b", *before)
+		require.Equal(t, "a", *after)
+		require.Equal(t, "arg", *a)
+	}
+
+	require.NoError(t, app.Run([]string{"app"}))
+	require.True(t, ex, "Exec wasn't called")
+}
+
+func TestConfigInvalidValue(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected int
+		warning  string
+	}{
+		{[]string{"app", "sub"}, 1, "Warning: ignoring the config value of option -n: invalid value \"x\": "},
+		{[]string{"app", "sub", "-n", "2"}, 2, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %#v", cas)
+
+		source, err := JSONConfig(strings.NewReader(`{"app sub": {"n": "x", "ns": [1, "y"]}}`))
+		require.NoError(t, err)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Config(source)
+
+		ex := false
+		app.Command("sub", "", func(cmd *Cmd) {
+			n := cmd.IntOpt("n", 1, "")
+			ns := cmd.IntsOpt("ns", []int{1, 2}, "")
+
+			cmd.Action = func() {
+				ex = true
+				require.Equal(t, cas.expected, *n)
+				require.Equal(t, []int{1, 2}, *ns)
+			}
+		})
+
+		var stdErr string
+		restore := captureAndRestoreOutput(nil, &stdErr)
+		err = app.Run(cas.args)
+		restore()
+
+		require.NoError(t, err)
+		require.True(t, ex, "Exec wasn't called")
+
+		require.Contains(t, stdErr, "Warning: ignoring the config value of option --ns: invalid value \"1,y\": ")
+		if cas.warning == "" {
+			require.NotContains(t, stdErr, "option -n:")
+		} else {
+			require.Contains(t, stdErr, cas.warning)
+		}
+	}
+}
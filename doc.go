@@ -20,7 +20,7 @@ Finally, in your main func, call Run on the app:
 
 Options
 
-To add a (global) option, call one of the (String[s]|Int[s]|Bool)Opt methods on the app:
+To add a (global) option, call one of the (String[s]|Int[s]|Float[s]|Duration[s]|Bool)Opt methods on the app:
 
 	recursive := cp.BoolOpt("R recursive", false, "recursively copy the src to dst")
 
@@ -30,7 +30,7 @@ To add a (global) option, call one of the (String[s]|Int[s]|Bool)Opt methods on
 
 * The third parameter is the option description, as will be shown in the help messages
 
-There is also a second set of methods Bool, String, Int, Strings and Ints, which accepts a struct describing the option:
+There is also a second set of methods Bool, String, Int, Float, Duration, Strings, Ints, Floats and Durations, which accepts a struct describing the option:
 
 	recursive = cp.Bool(BoolOpt{
 		Name:  "R",
@@ -44,6 +44,20 @@ There EnvVar field is a space separated list of environment variables names to b
 The result is a pointer to a value which will be populated after parsing the command line arguments.
 You can access the values in the Action func.
 
+To restrict an option (or an argument) to a fixed set of values, use the Enum and Enums methods:
+
+	format := cp.EnumOpt("f format", "table", []string{"json", "yaml", "table"}, "Output format")
+
+Any value outside of the choices list is rejected when the command line is parsed, and the accepted values are listed in the help message.
+
+Key/value pairs can be collected in a map with the StringMap methods:
+
+	labels := cp.StringMapOpt("l label", nil, "Labels to apply")
+
+Every occurrence of the option adds a pair to the map, e.g. `-l env=prod -l team=ops`, and the last value wins when a key is repeated,
+unless RejectDuplicateKeys is set.
+When initialized from an environment variable, the pairs are separated by commas: `env=prod,team=ops`.
+
 In the command line, mow.cli accepts the following syntaxes
 
 * For boolean options:
@@ -52,8 +66,9 @@ In the command line, mow.cli accepts the following syntaxes
 	-f=false : a single dash for the one letter names, equal sign followed by true or false
 	--force :  double dash for longer option names
 	-it : mow.cli supports option folding, this is equivalent to: -i -t
+	--no-force : for the options with Negatable set, double dash and no- for longer option names, sets the option to false
 
-* For string, int options:
+* For string, int, float, duration options:
 
 	-e=value : single dash for one letter names, equal sign followed by the value
 	-e value : single dash for one letter names, space followed by the value
@@ -61,13 +76,30 @@ In the command line, mow.cli accepts the following syntaxes
 	--extra=value : double dash for longer option names, equal sign followed by the value
 	--extra value : double dash for longer option names, space followed by the value
 
-* For slice options (StringsOpt, IntsOpt): repeat the option to accumulate the values in the resulting slice:
+* For string, int and enum options with OptionalValue set: the value can only follow an equal sign, and the option is set to its ImplicitValue otherwise:
+
+	--color : the option is set to its ImplicitValue
+	--color=never : the option is set to never
+
+* For slice options (StringsOpt, IntsOpt, FloatsOpt, DurationsOpt): repeat the option to accumulate the values in the resulting slice:
 
 	-e PATH:/bin -e PATH:/usr/bin : resulting slice contains ["/bin", "/usr/bin"]
 
+* For counter options (CountOpt): repeat the option to increment the resulting int (the spec must allow the repetition, e.g. [-v...]):
+
+	-vvv : resulting value is 3
+	--verbose -v : resulting value is 2
+
+When enabled with Abbreviations, an unambiguous prefix of a long option name or of a command alias can be used instead of the full name,
+and an ambiguous prefix is reported as an error listing the matching names:
+
+	app.Abbreviations(true)
+
+	--verb : same as --verbose, unless another long option starts with --verb
+
 Arguments
 
-To accept arguments, you need to explicitly declare them by calling one of the (String[s]|Int[s]|Bool)Arg methods on the app:
+To accept arguments, you need to explicitly declare them by calling one of the (String[s]|Int[s]|Float[s]|Duration[s]|Bool)Arg methods on the app:
 
 	src := cp.StringArg("SRC", "", "the file to copy")
 	dst := cp.StringArg("DST", "", "the destination")
@@ -78,7 +110,7 @@ To accept arguments, you need to explicitly declare them by calling one of the (
 
 * The third parameter is the argument description, as will be shown in the help messages
 
-There is also a second set of methods Bool, String, Int, Strings and Ints, which accepts structs describing the argument:
+There is also a second set of methods Bool, String, Int, Float, Duration, Strings, Ints, Floats and Durations, which accepts structs describing the argument:
 
 	src = cp.Strings(StringsArg{
 		Name:  "SRC",
@@ -94,12 +126,143 @@ EnvVar accepts a space separated list of environment variables names to be used
 
 If SetByUser is specified (by passing a pointer to a bool variable), it will be set to true only if the user explicitly sets the argument.
 
+If Validate is specified, it will be called with the argument value once it was set from the command line or from an environment variable.
+A returned error aborts the parsing and is reported to the user together with the argument name.
+The option structs (BoolOpt, StringOpt, ...) accept the same Validate field.
+
 
 The result is a pointer to a value that will be populated after parsing the command line arguments.
 You can access the values in the Action func.
 
 
 
+Configuration
+
+Options and arguments can also be initialized from a configuration source, e.g. a JSON file:
+
+	app := cli.App("docker", "A self-sufficient runtime for linux containers")
+	config, err := cli.JSONConfigFile("/etc/docker-cli.json")
+	if err != nil {
+		// handle the error
+	}
+	app.Config(config)
+
+The JSON document is keyed by the command path, then by the option long name (or the argument name):
+
+	{
+		"docker": {"debug": true},
+		"docker run": {"memory": "1g", "env": ["A=1", "B=2"]}
+	}
+
+Any format can be supported by implementing the ConfigSource interface.
+
+Values are applied with the following precedence (highest first): command line, env variables, config source, initial value.
+Invalid config values are ignored, with a warning when the command is run.
+
+OptionValueSource and ArgValueSource tell where the value of an option or an argument came from,
+and which environment variable was used when several are listed:
+
+	src, envVar := app.OptionValueSource("memory")
+	fmt.Printf("memory=%s (from %s %s)\n", *memory, src, envVar)
+
+
+Shell completion
+
+WriteBashCompletion writes a bash completion script for the app.
+The script calls back the app binary (through a hidden entry point) to complete commands, aliases, option names and enum values:
+
+	app.Command("completion", "Print the bash completion script", func(cmd *cli.Cmd) {
+		cmd.Action = func() {
+			app.WriteBashCompletion(os.Stdout)
+		}
+	})
+
+And then, in a shell:
+
+	source <(docker completion)
+
+Options and arguments can provide their own candidates with a Complete function,
+which is called when the cursor is on their value, as determined by the command spec.
+Like the enum choices, the returned candidates which do not start with the typed prefix are filtered out:
+
+	cp.String(cli.StringArg{
+		Name:     "CONTAINER",
+		Desc:     "the container to copy from",
+		Complete: func(prefix string) []string { return listContainers(prefix) },
+	})
+
+WriteZshCompletion and WriteFishCompletion generate zsh and fish scripts from the commands tree.
+These shells show the commands and options descriptions next to the candidates.
+The scripts only call back the app binary for the values of the options and arguments with a Complete function:
+
+	source <(docker completion zsh)
+	docker completion fish | source
+
+
+Documentation
+
+WriteManPages generates a section 1 man page per command (e.g. docker.1, docker-run.1) in a directory,
+with the command description, spec, arguments, options, env variables, sub commands and SEE ALSO references:
+
+	if err := app.WriteManPages("man/"); err != nil {
+		// handle the error
+	}
+
+WriteMarkdownDocs does the same with one linked Markdown file per command (e.g. docker.md, docker-run.md),
+listing the arguments and options with their env variables and default values in tables.
+WriteMarkdownDoc writes all the commands in a single Markdown document instead.
+
+
+Custom help
+
+Commands with many options can list them under named sections with the Group field.
+The sections are shown in declaration order, after the ungrouped options (and likewise for arguments):
+
+	cp.String(cli.StringOpt{Name: "H host", Desc: "The daemon host", Group: "Connection options"})
+	cp.Bool(cli.BoolOpt{Name: "q quiet", Desc: "Only print the IDs", Group: "Output options"})
+
+Options and arguments with the Hidden field set, and commands declared with the HiddenCommand() option,
+are left out of the help messages, the generated documentation and the shell completion, but can still be used:
+
+	app.Bool(cli.BoolOpt{Name: "trace", Desc: "Trace the daemon calls", Hidden: true})
+	app.Command("debug", "Internal debugging commands", initDebug, cli.HiddenCommand())
+
+Options and arguments can be marked as deprecated with a message using their Deprecated field, individual env variables
+using the DeprecatedEnvVars field, and commands using the DeprecatedCommand option.
+They are still accepted, but a warning is printed to stderr when they are actually used, and they are marked as such in the help messages:
+
+	app.String(cli.StringOpt{Name: "endpoint", EnvVar: "APP_ENDPOINT APP_HOST", DeprecatedEnvVars: map[string]string{"APP_HOST": "use $APP_ENDPOINT instead"}})
+	app.String(cli.StringOpt{Name: "host", Desc: "The daemon host", Deprecated: "use --endpoint instead"})
+	app.Command("rmi", "Remove images", initRmi, cli.DeprecatedCommand("use image rm instead"))
+
+When a command or a long option is mistyped, the error message suggests the closest existing ones:
+
+	Error: incorrect usage
+	Did you mean 'status'?
+
+Suggestions sets the maximum edit distance of the suggestions (2 by default, a zero or negative distance disables them):
+
+	app.Suggestions(1)
+
+The default help messages descriptions are wrapped to the width given by the COLUMNS env variable, if set,
+with the continuation lines indented to the descriptions column.
+HelpWidth sets that width explicitly (a negative width disables the wrapping):
+
+	app.HelpWidth(100)
+
+The help messages layout can be customized by setting the HelpRenderer field of the app or of a command
+(commands inherit the renderer of their parent).
+A renderer receives a Help model describing the command usage, arguments, options (with their env variables, choices and default values)
+and sub commands.
+
+HelpTemplate returns a renderer executing a text/template with that model:
+
+	app.HelpRenderer = cli.HelpTemplate(`Usage: {{.Usage}}
+	{{range .Options}}
+	  {{join .Names ", "}}	{{.Desc}}{{end}}
+	`)
+
+
 Operators
 
 The -- operator marks the end of options.
@@ -169,6 +332,14 @@ You can also add sub commands by calling Command on the Cmd struct:
 
 This could go on to any depth if need be.
 
+Options declared with the Persistent field set are also accepted after the name of any of the command sub commands,
+at any depth, and are listed under "Global options" in their help messages:
+
+	verbose := app.Bool(cli.BoolOpt{Name: "v verbose", Desc: "Verbose mode", Persistent: true})
+
+which allows you to invoke app -v job list as well as app job list -v, both setting verbose.
+A Required persistent option is left out of the auto-generated spec and is instead checked when the sub command to run is parsed.
+
 mow.cli also supports command aliases. For example:
 
 	app.Command("start run r", "start doing things", cli.ActionCommand(func() { start() }))
@@ -201,9 +372,9 @@ This way, the command specific variables scope is limited to this function.
 
 Custom types
 
-Out of the box, mow.cli supports the following types for options and arguments: bool, string, int, strings (slice of strings) and ints (slice of ints)
+Out of the box, mow.cli supports the following types for options and arguments: bool, string, int, float64, time.Duration, strings (slice of strings), ints (slice of ints), floats (slice of float64) and durations (slice of time.Duration)
 
-You can however extend mow.cli to handle other types, e.g. `time.Duration`, `float64`, or even your own struct types for example.
+You can however extend mow.cli to handle other types, e.g. `net.IP`, `*url.URL`, or even your own struct types for example.
 
 To do so, you'll need to:
 
@@ -335,6 +506,23 @@ Here's a diagram which shows in when and in which order multiple Before and Afte
 	| app.After  <---------------+ cmd.After  <-------------+  sub_cmd.After <---------+
 	+------------+    always     +------------+    always   +----------------+      always
 
+Validation
+
+Constraints spanning multiple options or arguments, which can't be expressed in a spec string, can be checked in a command's Validate function:
+
+	app.Validate = func() error {
+		if *since > *until {
+			return fmt.Errorf("--since must be before --until")
+		}
+		return nil
+	}
+
+Validate is called once the command's options and arguments are parsed, before any Before interceptor.
+The Validate functions of the parent commands are called once the sub command to run is parsed too, root first,
+so that they see the persistent options given after the sub command name.
+A returned error is handled like an incorrect usage: it is printed together with the help message,
+and the command's ErrorHandling policy is applied.
+
 Spec
 
 An app or command's call syntax can be customized using spec strings.
@@ -491,7 +679,9 @@ By default, and unless a spec string is set by the user, mow.cli auto-generates
 
 * Start with an empty spec string
 
-* If at least one option was declared, append "[OPTIONS]" to the spec string
+* For every option declared with Required: true, append its first name, in the order of declaration, to the spec string
+
+* If at least one non required option was declared, append "[OPTIONS]" to the spec string
 
 * For every declared argument, append it, in the order of declaration, to the spec string
 
@@ -508,6 +698,13 @@ The auto-generated spec string would be:
 
 Which should suffice for simple cases. If not, the spec string has to be set explicitly.
 
+Marking the memory option as required:
+	memory := cmd.String(cli.StringOpt{Name: "m memory", Desc: "Memory limit", Required: true})
+would produce this spec string instead:
+	-m [OPTIONS] IMAGE ARG
+
+A required option can still be satisfied by its environment variables. The Required field is ignored when the spec string is set explicitly.
+
 
 Exiting
 
@@ -7,4 +7,5 @@ import (
 var (
 	errHelpRequested    = errors.New("Help requested")
 	errVersionRequested = errors.New("Version requested")
+	errIncorrectUsage   = errors.New("incorrect usage")
 )
@@ -172,41 +172,114 @@ func (s *state) parse(args []string) error {
 		return err
 	}
 	if !ok {
-		return fmt.Errorf("incorrect usage")
+		return errIncorrectUsage
 	}
 
 	for opt, vs := range pc.opts {
-		if multiValued, ok := opt.value.(multiValued); ok {
-			multiValued.Clear()
-			opt.valueSetFromEnv = false
-		}
-		for _, v := range vs {
-			if err := opt.value.Set(v); err != nil {
-				return err
-			}
-		}
-
-		if opt.valueSetByUser != nil {
-			*opt.valueSetByUser = true
+		if err := opt.setFromCommandLine(vs, false); err != nil {
+			return err
 		}
 	}
 
 	for arg, vs := range pc.args {
 		if multiValued, ok := arg.value.(multiValued); ok {
 			multiValued.Clear()
-			arg.valueSetFromEnv = false
 		}
 		for _, v := range vs {
 			if err := arg.value.Set(v); err != nil {
-				return err
+				return fmt.Errorf("invalid value %q for argument %s: %s", v, arg.name, err.Error())
 			}
 		}
 
+		if arg.deprecated != "" {
+			warnDeprecated("argument "+arg.name, arg.deprecated)
+		}
+		arg.valueSource = ValueSourceCommandLine
+		arg.valueEnvVar = ""
 		if arg.valueSetByUser != nil {
 			*arg.valueSetByUser = true
 		}
 	}
 
+	s.warnDeprecatedEnvVars()
+	s.warnInvalidConfigValues()
+
+	return s.validate(pc)
+}
+
+// setFromCommandLine sets the values of an option found in the call arguments, replacing its current value
+// or, if accumulate is set and the option was already found in the call arguments, adding to it for multi-valued options
+func (o *opt) setFromCommandLine(vs []string, accumulate bool) error {
+	if multiValued, ok := o.value.(multiValued); ok && !(accumulate && o.valueSource == ValueSourceCommandLine) {
+		multiValued.Clear()
+	}
+	for _, v := range vs {
+		if err := o.value.Set(v); err != nil {
+			return fmt.Errorf("invalid value %q for option %s: %s", v, strings.Join(o.names, ", "), err.Error())
+		}
+	}
+
+	if o.deprecated != "" {
+		warnDeprecated("option "+strings.Join(o.names, ", "), o.deprecated)
+	}
+	o.valueSource = ValueSourceCommandLine
+	o.valueEnvVar = ""
+	if o.valueSetByUser != nil {
+		*o.valueSetByUser = true
+	}
+	return nil
+}
+
+// warnDeprecatedEnvVars warns about the deprecated env variables which provided the values of options or arguments
+func (s *state) warnDeprecatedEnvVars() {
+	for _, opt := range s.cmd.options {
+		if msg, deprecated := opt.deprecatedEnvVars[opt.valueEnvVar]; deprecated && opt.valueSource == ValueSourceEnv {
+			warnDeprecated("env variable $"+opt.valueEnvVar, msg)
+		}
+	}
+
+	for _, arg := range s.cmd.args {
+		if msg, deprecated := arg.deprecatedEnvVars[arg.valueEnvVar]; deprecated && arg.valueSource == ValueSourceEnv {
+			warnDeprecated("env variable $"+arg.valueEnvVar, msg)
+		}
+	}
+}
+
+// warnInvalidConfigValues warns about the options and arguments whose invalid config values were ignored,
+// unless the call arguments provided them
+func (s *state) warnInvalidConfigValues() {
+	for _, opt := range s.cmd.options {
+		if opt.configErr != nil && opt.valueSource != ValueSourceCommandLine {
+			warnInvalidConfig("option "+strings.Join(opt.names, ", "), opt.configErr)
+		}
+	}
+
+	for _, arg := range s.cmd.args {
+		if arg.configErr != nil && arg.valueSource != ValueSourceCommandLine {
+			warnInvalidConfig("argument "+arg.name, arg.configErr)
+		}
+	}
+}
+
+func (s *state) validate(pc parseContext) error {
+	for _, opt := range s.cmd.options {
+		if opt.valueSource == ValueSourceDefault || opt.validate == nil {
+			continue
+		}
+		if err := opt.validate(); err != nil {
+			return fmt.Errorf("invalid value for option %s: %s", strings.Join(opt.names, ", "), err.Error())
+		}
+	}
+
+	for _, arg := range s.cmd.args {
+		if arg.valueSource == ValueSourceDefault || arg.validate == nil {
+			continue
+		}
+		if err := arg.validate(); err != nil {
+			return fmt.Errorf("invalid value for argument %s: %s", arg.name, err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -252,3 +325,53 @@ func (s *state) apply(args []string, pc parseContext) (bool, error) {
 	}
 	return false, nil
 }
+
+type expectKey struct {
+	s             *state
+	rem           int
+	rejectOptions bool
+}
+
+// expectedArgs returns the arguments which could match the word following args, args being the words already typed
+func (s *state) expectedArgs(args []string) []*arg {
+	res := []*arg{}
+	s.collectExpectedArgs(args, false, map[expectKey]bool{}, &res)
+	return res
+}
+
+func (s *state) collectExpectedArgs(args []string, rejectOptions bool, visited map[expectKey]bool, res *[]*arg) {
+	key := expectKey{s, len(args), rejectOptions}
+	if visited[key] {
+		return
+	}
+	visited[key] = true
+
+	if len(args) > 0 && !rejectOptions && args[0] == "--" {
+		rejectOptions = true
+		args = args[1:]
+	}
+
+	for _, tr := range s.transitions {
+		if a, ok := tr.matcher.(*arg); ok && len(args) == 0 {
+			if !containsArg(*res, a) {
+				*res = append(*res, a)
+			}
+			continue
+		}
+
+		pc := newParseContext()
+		pc.rejectOptions = rejectOptions
+		if ok, rem := tr.matcher.match(args, &pc); ok {
+			tr.next.collectExpectedArgs(rem, pc.rejectOptions, visited, res)
+		}
+	}
+}
+
+func containsArg(args []*arg, a *arg) bool {
+	for _, x := range args {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,548 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"unicode/utf8"
+)
+
+/*
+Help is the structured model of a command help message, as passed to a HelpRenderer
+*/
+type Help struct {
+	// The command path, e.g. "docker run"
+	Path string
+	// The usage line, e.g. "docker run [OPTIONS] IMAGE [COMMAND] [ARG...]"
+	Usage string
+	// The command description, or its long description if set and the long help was requested (e.g. with --help)
+	Desc string
+	// The command arguments in declaration order
+	Args []HelpArg
+	// The command options in declaration order
+	Options []HelpOption
+	// The command sub commands in declaration order
+	Commands []HelpCommand
+	// The width to wrap the help message to, 0 when it should not be wrapped
+	Width int
+}
+
+/*
+HelpArg describes an argument in a Help model
+*/
+type HelpArg struct {
+	// The argument name, e.g. "SRC"
+	Name string
+	// The argument description
+	Desc string
+	// The environment variables which can be used to initialize the argument
+	EnvVars []string
+	// The deprecation messages of some of the EnvVars
+	DeprecatedEnvVars map[string]string
+	// The accepted values for enum arguments
+	Choices []string
+	// The argument current value, or an empty string if it is hidden or is the type's default
+	Value string
+	// The help section the argument is listed under, empty for the default one
+	Group string
+	// The deprecation message, empty if the argument is not deprecated
+	Deprecated string
+}
+
+/*
+HelpOption describes an option in a Help model
+*/
+type HelpOption struct {
+	// The option names with their dashes, e.g. ["-f", "--force"]
+	Names []string
+	// The option description
+	Desc string
+	// Whether the option is mandatory
+	Required bool
+	// The environment variables which can be used to initialize the option
+	EnvVars []string
+	// The deprecation messages of some of the EnvVars
+	DeprecatedEnvVars map[string]string
+	// The accepted values for enum options
+	Choices []string
+	// The option current value, or an empty string if it is hidden or is the type's default
+	Value string
+	// The help section the option is listed under, empty for the default one
+	Group string
+	// The deprecation message, empty if the option is not deprecated
+	Deprecated string
+}
+
+/*
+HelpCommand describes a sub command in a Help model
+*/
+type HelpCommand struct {
+	// The command name followed by its aliases
+	Aliases []string
+	// The command description
+	Desc string
+	// The deprecation message, empty if the command is not deprecated
+	Deprecated string
+}
+
+/*
+HelpRenderer renders a command help message.
+
+Set the HelpRenderer field of the app or of a command to customize the help messages layout.
+Commands inherit the HelpRenderer of their parent unless they have their own.
+*/
+type HelpRenderer interface {
+	RenderHelp(w io.Writer, help Help) error
+}
+
+/*
+HelpTemplate returns a HelpRenderer which executes a text/template with the command Help model as its data.
+
+Besides the standard functions, the template can call join (strings.Join):
+
+	app.HelpRenderer = cli.HelpTemplate(`Usage: {{.Usage}}
+	{{range .Options}}
+	  {{join .Names ", "}}	{{.Desc}}{{end}}
+	`)
+
+HelpTemplate panics if the template cannot be parsed
+*/
+func HelpTemplate(text string) HelpRenderer {
+	tmpl := template.Must(template.New("help").Funcs(template.FuncMap{"join": strings.Join}).Parse(text))
+	return templateHelpRenderer{tmpl}
+}
+
+type templateHelpRenderer struct {
+	tmpl *template.Template
+}
+
+func (r templateHelpRenderer) RenderHelp(w io.Writer, help Help) error {
+	return r.tmpl.Execute(w, help)
+}
+
+const (
+	helpColumnMinWidth = 15
+	helpColumnPadding  = 3
+	// descriptions are not wrapped when less than this width is left for them
+	helpDescMinWidth = 20
+)
+
+type defaultHelpRenderer struct{}
+
+func (defaultHelpRenderer) RenderHelp(out io.Writer, help Help) error {
+	fmt.Fprintf(out, "\nUsage: %s\n\n", help.Usage)
+
+	if len(help.Desc) > 0 {
+		fmt.Fprintf(out, "%s\n", strings.Join(wrapText(help.Desc, help.Width), "\n"))
+	}
+
+	descWidth := 0
+	if help.Width > 0 {
+		descWidth = help.Width - helpDescColumn(help)
+	}
+
+	w := tabwriter.NewWriter(out, helpColumnMinWidth, 1, helpColumnPadding, ' ', 0)
+
+	for _, section := range helpSections("Arguments", helpArgGroups(help.Args)) {
+		fmt.Fprintf(w, "\t\n%s:\t\n", section.title)
+
+		for _, i := range section.indices {
+			arg := help.Args[i]
+			var (
+				deprecated = formatDeprecatedForHelp(arg.Deprecated)
+				env        = formatEnvVarsForHelp(arg.EnvVars, arg.DeprecatedEnvVars)
+				choices    = formatChoicesForHelp(arg.Choices)
+				value      = formatValueForHelp(arg.Value)
+			)
+			fmt.Fprintf(w, "  %s\t%s\n", arg.Name, wrapHelpDesc(joinStrings(arg.Desc, deprecated, env, choices, value), descWidth))
+		}
+	}
+
+	for _, section := range helpSections("Options", helpOptionGroups(help.Options)) {
+		fmt.Fprintf(w, "\t\n%s:\t\n", section.title)
+
+		for _, i := range section.indices {
+			opt := help.Options[i]
+			var (
+				optNames   = formatOptNamesForHelp(opt.Names)
+				deprecated = formatDeprecatedForHelp(opt.Deprecated)
+				required   = formatRequiredForHelp(opt.Required)
+				env        = formatEnvVarsForHelp(opt.EnvVars, opt.DeprecatedEnvVars)
+				choices    = formatChoicesForHelp(opt.Choices)
+				value      = formatValueForHelp(opt.Value)
+			)
+			fmt.Fprintf(w, "  %s\t%s\n", optNames, wrapHelpDesc(joinStrings(opt.Desc, deprecated, required, env, choices, value), descWidth))
+		}
+	}
+
+	if len(help.Commands) > 0 {
+		fmt.Fprint(w, "\t\nCommands:\t\n")
+
+		for _, c := range help.Commands {
+			fmt.Fprintf(w, "  %s\t%s\n", strings.Join(c.Aliases, ", "), wrapHelpDesc(joinStrings(c.Desc, formatDeprecatedForHelp(c.Deprecated)), descWidth))
+		}
+	}
+
+	if len(help.Commands) > 0 {
+		fmt.Fprintf(w, "\t\nRun '%s COMMAND --help' for more information on a command.\n", help.Path)
+	}
+
+	return w.Flush()
+}
+
+// helpDescColumn computes the column at which the tabwriter will align the descriptions
+func helpDescColumn(help Help) int {
+	cells := []string{}
+	for _, section := range helpSections("Arguments", helpArgGroups(help.Args)) {
+		cells = append(cells, section.title+":")
+	}
+	for _, arg := range help.Args {
+		cells = append(cells, "  "+arg.Name)
+	}
+	for _, section := range helpSections("Options", helpOptionGroups(help.Options)) {
+		cells = append(cells, section.title+":")
+	}
+	for _, opt := range help.Options {
+		cells = append(cells, "  "+formatOptNamesForHelp(opt.Names))
+	}
+	if len(help.Commands) > 0 {
+		cells = append(cells, "Commands:")
+	}
+	for _, c := range help.Commands {
+		cells = append(cells, "  "+strings.Join(c.Aliases, ", "))
+	}
+
+	res := helpColumnMinWidth
+	for _, cell := range cells {
+		if w := utf8.RuneCountInString(cell) + helpColumnPadding; w > res {
+			res = w
+		}
+	}
+	return res
+}
+
+// globalOptionsGroup is the help message section listing the persistent options inherited from the parent commands
+const globalOptionsGroup = "Global options"
+
+type helpSection struct {
+	title   string
+	indices []int
+}
+
+// helpSections splits items in sections given their groups:
+// the ungrouped ones under defaultTitle first, then the named groups in declaration order
+func helpSections(defaultTitle string, groups []string) []helpSection {
+	res := []helpSection{{title: defaultTitle}}
+	idx := map[string]int{"": 0}
+	for i, group := range groups {
+		if _, found := idx[group]; !found {
+			idx[group] = len(res)
+			res = append(res, helpSection{title: group})
+		}
+		res[idx[group]].indices = append(res[idx[group]].indices, i)
+	}
+
+	if len(res[0].indices) == 0 {
+		return res[1:]
+	}
+	return res
+}
+
+func helpArgGroups(args []HelpArg) []string {
+	res := make([]string, len(args))
+	for i, arg := range args {
+		res[i] = arg.Group
+	}
+	return res
+}
+
+func helpOptionGroups(opts []HelpOption) []string {
+	res := make([]string, len(opts))
+	for i, opt := range opts {
+		res[i] = opt.Group
+	}
+	return res
+}
+
+// wrapHelpDesc wraps a description to width, indenting the continuation lines to the description column
+func wrapHelpDesc(desc string, width int) string {
+	if width < helpDescMinWidth {
+		return desc
+	}
+	return strings.Join(wrapText(desc, width), "\n\t")
+}
+
+// wrapText splits a text into lines no longer than width, except for the words which are longer than width
+func wrapText(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+
+	res := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		line, lineLen := "", 0
+		for _, word := range strings.Fields(paragraph) {
+			wordLen := utf8.RuneCountInString(word)
+			switch {
+			case lineLen == 0:
+				line, lineLen = word, wordLen
+			case lineLen+1+wordLen <= width:
+				line, lineLen = line+" "+word, lineLen+1+wordLen
+			default:
+				res = append(res, line)
+				line, lineLen = word, wordLen
+			}
+		}
+		res = append(res, line)
+	}
+	return res
+}
+
+// help builds the help model of the command
+func (c *Cmd) help(longDesc bool) Help {
+	path := cmdPath(c)
+	res := Help{
+		Path:     path,
+		Usage:    path,
+		Desc:     c.desc,
+		Args:     []HelpArg{},
+		Options:  []HelpOption{},
+		Commands: []HelpCommand{},
+		Width:    c.helpWidth(),
+	}
+
+	if spec := strings.TrimSpace(c.helpSpec()); len(spec) > 0 {
+		res.Usage += " " + spec
+	}
+	if len(c.visibleCommands()) > 0 {
+		res.Usage += " COMMAND [arg...]"
+	}
+
+	if longDesc && len(c.LongDesc) > 0 {
+		res.Desc = c.LongDesc
+	}
+
+	for _, arg := range c.args {
+		if arg.hidden {
+			continue
+		}
+		res.Args = append(res.Args, HelpArg{
+			Name:              arg.name,
+			Desc:              arg.desc,
+			EnvVars:           strings.Fields(arg.envVar),
+			DeprecatedEnvVars: arg.deprecatedEnvVars,
+			Choices:           valueChoicesForHelp(arg.value),
+			Value:             helpValue(arg.hideValue, arg.value),
+			Group:             arg.group,
+			Deprecated:        arg.deprecated,
+		})
+	}
+
+	for _, opt := range c.options {
+		if opt.hidden {
+			continue
+		}
+		res.Options = append(res.Options, helpOption(opt, opt.group, c.requiredOption(opt)))
+	}
+
+	for _, opt := range c.globalOptions {
+		if opt.hidden {
+			continue
+		}
+		res.Options = append(res.Options, helpOption(opt, globalOptionsGroup, c.requiredOption(opt)))
+	}
+
+	for _, sub := range c.visibleCommands() {
+		res.Commands = append(res.Commands, HelpCommand{
+			Aliases:    sub.aliases,
+			Desc:       sub.desc,
+			Deprecated: sub.deprecated,
+		})
+	}
+
+	return res
+}
+
+func helpOption(opt *opt, group string, required bool) HelpOption {
+	return HelpOption{
+		Names:             opt.helpNames(),
+		Desc:              opt.desc,
+		Required:          required,
+		EnvVars:           strings.Fields(opt.envVar),
+		DeprecatedEnvVars: opt.deprecatedEnvVars,
+		Choices:           valueChoicesForHelp(opt.value),
+		Value:             helpValue(opt.hideValue, opt.value),
+		Group:             group,
+		Deprecated:        opt.deprecated,
+	}
+}
+
+// requiredOption tells whether an option of the command, or inherited from one of its parents, is required,
+// i.e. whether its Required field is set and the spec of the command declaring it was generated
+func (c *Cmd) requiredOption(o *opt) bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		for _, declared := range cmd.options {
+			if declared == o {
+				return o.required && cmd.specGenerated
+			}
+		}
+	}
+	return false
+}
+
+// helpSpec returns the command spec as shown in the help messages, i.e. without the hidden options and arguments
+// and with the long names of the negatable options in the --[no-]name form
+func (c *Cmd) helpSpec() string {
+	spec := c.autoSpec(true)
+	if !c.specGenerated {
+		spec = c.withoutHiddenItems(c.Spec)
+	}
+
+	tokens, err := uTokenize(spec)
+	if err != nil {
+		return spec
+	}
+
+	for i := len(tokens) - 1; i >= 0; i-- {
+		tk := tokens[i]
+		if tk.typ != utLongOpt {
+			continue
+		}
+		if opt, found := c.optionsIdx[tk.val]; found && opt.negatable && !opt.isNegatedName(tk.val) {
+			spec = spec[:tk.pos] + "--[no-]" + tk.val[2:] + spec[tk.pos+len(tk.val):]
+		}
+	}
+	return spec
+}
+
+// withoutHiddenItems removes the hidden options and arguments from spec, together with their values and repetitions,
+// and then the groups and choices left empty
+func (c *Cmd) withoutHiddenItems(spec string) string {
+	tokens, err := uTokenize(spec)
+	if err != nil {
+		return spec
+	}
+
+	removed := false
+	for i := len(tokens) - 1; i >= 0; i-- {
+		tk := tokens[i]
+		start, end := tk.pos, tk.pos+len(tk.val)
+		switch tk.typ {
+		case utShortOpt, utLongOpt:
+			if opt, found := c.optionsIdx[tk.val]; !found || !opt.hidden {
+				continue
+			}
+		case utPos:
+			if arg, found := c.argsIdx[tk.val]; !found || !arg.hidden {
+				continue
+			}
+		case utOptSeq:
+			visible := ""
+			for _, name := range tk.val {
+				if opt, found := c.optionsIdx["-"+string(name)]; !found || !opt.hidden {
+					visible += string(name)
+				}
+			}
+			if visible == tk.val {
+				continue
+			}
+			if visible != "" {
+				spec, removed = spec[:start]+visible+spec[end:], true
+				continue
+			}
+			start--
+		default:
+			continue
+		}
+
+		for j := i + 1; j < len(tokens) && tokens[j].pos == end && (tokens[j].typ == utOptValue || tokens[j].typ == utRep); j++ {
+			end += len(tokens[j].val)
+		}
+		spec, removed = spec[:start]+spec[end:], true
+	}
+
+	if !removed {
+		return spec
+	}
+	for tidied := true; tidied; {
+		spec, tidied = tidySpec(spec)
+	}
+
+	spec = strings.Join(strings.Fields(spec), " ")
+	for _, space := range []struct{ from, to string }{{"[ ", "["}, {"( ", "("}, {" ]", "]"}, {" )", ")"}} {
+		spec = strings.Replace(spec, space.from, space.to, -1)
+	}
+	return spec
+}
+
+// tidySpec removes the first empty group or dangling choice of spec, returning whether one was found
+func tidySpec(spec string) (string, bool) {
+	tokens, err := uTokenize(spec)
+	if err != nil {
+		return spec, false
+	}
+
+	typeAt := func(i int) uTokenType {
+		if i < 0 || i >= len(tokens) {
+			return ""
+		}
+		return tokens[i].typ
+	}
+
+	for i, tk := range tokens {
+		switch {
+		case tk.typ == utOpenSq && typeAt(i+1) == utCloseSq, tk.typ == utOpenPar && typeAt(i+1) == utClosePar:
+			end := tokens[i+1].pos + 1
+			if typeAt(i+2) == utRep && tokens[i+2].pos == end {
+				end += len(tokens[i+2].val)
+			}
+			return spec[:tk.pos] + spec[end:], true
+		case tk.typ == utChoice:
+			before, after := typeAt(i-1), typeAt(i+1)
+			if before == "" || before == utOpenSq || before == utOpenPar || before == utChoice ||
+				after == "" || after == utCloseSq || after == utClosePar {
+				return spec[:tk.pos] + spec[tk.pos+1:], true
+			}
+		}
+	}
+	return spec, false
+}
+
+// visibleCommands returns the sub commands which are not hidden, without initializing them
+func (c *Cmd) visibleCommands() []*Cmd {
+	res := []*Cmd{}
+	for _, sub := range c.commands {
+		if sub.hidden {
+			continue
+		}
+		res = append(res, sub)
+	}
+	return res
+}
+
+// helpWidth returns the width set with Cli.HelpWidth, or the value of the COLUMNS env variable
+func (c *Cmd) helpWidth() int {
+	switch {
+	case c.width < 0:
+		return 0
+	case c.width > 0:
+		return c.width
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 0
+}
+
+func valueChoicesForHelp(v flag.Value) []string {
+	if cv, ok := v.(choicesValued); ok {
+		return cv.Choices()
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHelpRenderer struct {
+	helps []Help
+	err   error
+}
+
+func (r *recordingHelpRenderer) RenderHelp(w io.Writer, help Help) error {
+	r.helps = append(r.helps, help)
+	return r.err
+}
+
+func TestHelpModel(t *testing.T) {
+	defer suppressOutput()()
+
+	exitCalled := false
+	defer exitShouldBeCalledWith(t, 0, &exitCalled)()
+
+	renderer := &recordingHelpRenderer{}
+
+	app := App("app", "App Desc")
+	app.HelpRenderer = renderer
+	app.Command("build b", "Build things", func(cmd *Cmd) {
+		cmd.LongDesc = "Build all the things"
+		cmd.Enum(EnumOpt{Name: "f format", Value: "json", Choices: []string{"json", "text"}, EnvVar: "FORMAT", Required: true, Desc: "Output format"})
+		cmd.String(StringOpt{Name: "token", Value: "secret", HideValue: true})
+		cmd.Strings(StringsArg{Name: "SRC", EnvVar: "SRC1 SRC2", Desc: "Sources"})
+		cmd.Command("image", "Build an image", func(cmd *Cmd) {})
+	})
+
+	app.Run([]string{"app", "build", "-h"})
+
+	require.True(t, exitCalled)
+	require.Equal(t, []Help{{
+		Path:  "app build",
+		Usage: "app build -f [OPTIONS] SRC COMMAND [arg...]",
+		Desc:  "Build all the things",
+		Args: []HelpArg{
+			{Name: "SRC", Desc: "Sources", EnvVars: []string{"SRC1", "SRC2"}},
+		},
+		Options: []HelpOption{
+			{Names: []string{"-f", "--format"}, Desc: "Output format", Required: true, EnvVars: []string{"FORMAT"}, Choices: []string{"json", "text"}, Value: `"json"`},
+			{Names: []string{"--token"}, EnvVars: []string{}},
+		},
+		Commands: []HelpCommand{
+			{Aliases: []string{"image"}, Desc: "Build an image"},
+		},
+	}}, renderer.helps)
+}
+
+func TestHelpTemplate(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	exitCalled := false
+	defer exitShouldBeCalledWith(t, 0, &exitCalled)()
+
+	app := App("app", "App Desc")
+	app.HelpRenderer = HelpTemplate(`{{.Usage}}
+{{range .Options}}{{join .Names "|"}}: {{.Desc}}
+{{end}}`)
+	app.Command("build", "Build things", func(cmd *Cmd) {
+		cmd.BoolOpt("f force", false, "Force it")
+	})
+
+	app.Run([]string{"app", "build", "-h"})
+
+	require.True(t, exitCalled)
+	require.Equal(t, "app build [OPTIONS]\n-f|--force: Force it\n", err)
+}
+
+func TestHelpRendererOverride(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	exitCalled := false
+	defer exitShouldBeCalledWith(t, 0, &exitCalled)()
+
+	app := App("app", "App Desc")
+	app.HelpRenderer = HelpTemplate("app template\n")
+	app.Command("build", "Build things", func(cmd *Cmd) {
+		cmd.HelpRenderer = HelpTemplate("build template\n")
+	})
+
+	app.Run([]string{"app", "build", "-h"})
+
+	require.True(t, exitCalled)
+	require.Equal(t, "build template\n", err)
+}
+
+func TestHelpRendererError(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "App Desc")
+	app.HelpRenderer = &recordingHelpRenderer{err: errors.New("boom")}
+	app.PrintHelp()
+
+	require.Equal(t, "Error: failed to render the help message: boom\n", err)
+}
+
+func TestHelpTemplatePanicsOnInvalidTemplate(t *testing.T) {
+	require.Panics(t, func() {
+		HelpTemplate("{{.Usage")
+	})
+}
+
+func TestHelpWrapping(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "An app with a rather long description which does not fit on a single line")
+	app.HelpWidth(50)
+	app.String(StringOpt{Name: "f format", Value: "json", EnvVar: "APP_FORMAT", Desc: "The format used to print the results of the command"})
+	app.Bool(BoolOpt{Name: "q", Desc: "Quiet"})
+	app.Command("build", "Build things", func(cmd *Cmd) {})
+
+	require.NoError(t, app.doInit())
+	app.PrintHelp()
+
+	require.Equal(t, `
+Usage: app [OPTIONS] COMMAND [arg...]
+
+An app with a rather long description which does
+not fit on a single line
+                 
+Options:         
+  -f, --format   The format used to print the
+                 results of the command (env
+                 $APP_FORMAT) (default "json")
+  -q             Quiet
+                 
+Commands:        
+  build          Build things
+                 
+Run 'app COMMAND --help' for more information on a command.
+`, err)
+}
+
+func TestHelpWidth(t *testing.T) {
+	defer os.Setenv("COLUMNS", os.Getenv("COLUMNS"))
+
+	cases := []struct {
+		columns  string
+		width    int
+		expected int
+	}{
+		{"", 0, 0},
+		{"120", 0, 120},
+		{"not a number", 0, 0},
+		{"120", 60, 60},
+		{"120", -1, 0},
+	}
+
+	for _, cas := range cases {
+		os.Setenv("COLUMNS", cas.columns)
+		app := App("app", "")
+		app.HelpWidth(cas.width)
+		require.Equal(t, cas.expected, app.help(false).Width, "COLUMNS=%q width=%d", cas.columns, cas.width)
+	}
+}
+
+func TestHelpWidthInherited(t *testing.T) {
+	app := App("app", "")
+	app.HelpWidth(42)
+	app.Command("build", "", func(cmd *Cmd) {})
+	require.NoError(t, app.doInit())
+
+	require.Equal(t, 42, app.commands[0].help(false).Width)
+}
+
+func TestWrapText(t *testing.T) {
+	require.Equal(t, []string{"a long text"}, wrapText("a long text", 0))
+	require.Equal(t, []string{"a long", "text"}, wrapText("a long text", 6))
+	require.Equal(t, []string{"a", "loooooong", "text"}, wrapText("a loooooong text", 6))
+	require.Equal(t, []string{"first", "", "second"}, wrapText("first\n\nsecond", 10))
+}
+
+func TestHelpGroups(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.String(StringOpt{Name: "H host", Group: "Connection options", Desc: "Host"})
+	app.Bool(BoolOpt{Name: "v verbose", Desc: "Verbose"})
+	app.String(StringOpt{Name: "o output", Group: "Output options", Desc: "Output"})
+	app.Int(IntOpt{Name: "p port", Group: "Connection options", Desc: "Port"})
+	app.String(StringArg{Name: "SRC", Group: "Sources", Desc: "Source"})
+
+	require.NoError(t, app.doInit())
+	app.PrintHelp()
+
+	require.Equal(t, `
+Usage: app [OPTIONS] SRC
+
+                      
+Sources:              
+  SRC                 Source
+                      
+Options:              
+  -v, --verbose       Verbose
+                      
+Connection options:   
+  -H, --host          Host
+  -p, --port          Port (default 0)
+                      
+Output options:       
+  -o, --output        Output
+`, err)
+}
+
+func TestHelpSections(t *testing.T) {
+	require.Equal(t, []helpSection{}, helpSections("Options", nil))
+	require.Equal(t, []helpSection{
+		{title: "Options", indices: []int{1, 4}},
+		{title: "b", indices: []int{0, 3}},
+		{title: "a", indices: []int{2}},
+	}, helpSections("Options", []string{"b", "", "a", "b", ""}))
+}
+
+func TestHiddenItems(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+	defer exitShouldNotCalled(t)()
+
+	var (
+		debugCalled bool
+		trace       *bool
+		dump        *string
+	)
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.Spec = "[-v] [--trace] [DUMP]"
+	app.BoolOpt("v verbose", false, "Verbose")
+	trace = app.Bool(BoolOpt{Name: "trace", Desc: "Trace", Hidden: true})
+	dump = app.String(StringArg{Name: "DUMP", Desc: "Dump file", Hidden: true})
+	app.Command("build", "Build things", func(cmd *Cmd) {})
+	app.Command("debug", "Debug internals", func(cmd *Cmd) {
+		cmd.Action = func() { debugCalled = true }
+	}, HiddenCommand())
+
+	require.NoError(t, app.doInit())
+	app.PrintHelp()
+
+	require.Contains(t, err, "Usage: app [-v] COMMAND [arg...]\n")
+	require.NotContains(t, err, "trace")
+	require.NotContains(t, err, "Trace")
+	require.NotContains(t, err, "DUMP")
+	require.NotContains(t, err, "Dump file")
+	require.NotContains(t, err, "debug")
+	require.Contains(t, err, "Verbose")
+	require.Contains(t, err, "build")
+
+	app.Action = func() {}
+	require.NoError(t, app.Run([]string{"app", "--trace", "core"}))
+	require.True(t, *trace)
+	require.Equal(t, "core", *dump)
+
+	app = App("app", "")
+	app.Command("debug", "Debug internals", func(cmd *Cmd) {
+		cmd.Action = func() { debugCalled = true }
+	}, HiddenCommand())
+	require.NoError(t, app.Run([]string{"app", "debug"}))
+	require.True(t, debugCalled)
+}
+
+func TestHiddenItemsUsage(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.String(StringOpt{Name: "secret", Required: true, Hidden: true})
+	app.Bool(BoolOpt{Name: "trace", Hidden: true})
+	app.String(StringArg{Name: "DUMP", Hidden: true})
+	app.String(StringArg{Name: "SRC"})
+	app.Command("debug", "Debug internals", func(cmd *Cmd) {}, HiddenCommand())
+
+	require.NoError(t, app.doInit())
+	require.Equal(t, "--secret [OPTIONS] DUMP SRC ", app.Spec)
+
+	app.PrintHelp()
+	require.Contains(t, err, "Usage: app SRC\n")
+	require.NotContains(t, err, "COMMAND")
+
+	cases := []struct {
+		spec     string
+		expected string
+	}{
+		{"[-v] [--trace] [DUMP]", "[-v]"},
+		{"[-v --trace]", "[-v]"},
+		{"[-vt] -x", "[-v] -x"},
+		{"[-t] -x", "-x"},
+		{"(-v | --trace) DUMP...", "(-v)"},
+		{"[--trace=<lvl>]... -x", "-x"},
+		{"[-x | [-v | -t]]", "[-x | [-v]]"},
+		{"-x [-t | DUMP]", "-x"},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %q", cas.spec)
+
+		cmd := &Cmd{name: "app", optionsIdx: map[string]*opt{}, argsIdx: map[string]*arg{}}
+		cmd.Bool(BoolOpt{Name: "v"})
+		cmd.Bool(BoolOpt{Name: "x"})
+		cmd.String(StringOpt{Name: "t trace", Hidden: true})
+		cmd.String(StringArg{Name: "DUMP", Hidden: true})
+
+		require.Equal(t, cas.expected, cmd.withoutHiddenItems(cas.spec))
+	}
+}
+
+func TestDeprecatedHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.String(StringOpt{Name: "endpoint", EnvVar: "APP_ENDPOINT HOST", DeprecatedEnvVars: map[string]string{"HOST": "use $APP_ENDPOINT instead"}, Desc: "Endpoint"})
+	app.String(StringOpt{Name: "host", Deprecated: "use --endpoint instead", Desc: "Host"})
+	app.Command("old", "Old command", func(cmd *Cmd) {}, DeprecatedCommand("use new instead"))
+
+	require.NoError(t, app.doInit())
+	app.PrintHelp()
+
+	require.Contains(t, err, "      --endpoint   Endpoint (env $APP_ENDPOINT, $HOST (deprecated))\n")
+	require.Contains(t, err, "      --host       Host (deprecated: use --endpoint instead)\n")
+	require.Contains(t, err, "  old              Old command (deprecated: use new instead)\n")
+}
+
+func TestNegatableHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.Spec = "[-q] [--cache]"
+	app.Bool(BoolOpt{Name: "q quiet", Desc: "Quiet"})
+	app.Bool(BoolOpt{Name: "c cache", Desc: "Use the cache", Negatable: true})
+
+	require.NoError(t, app.doInit())
+	app.PrintHelp()
+
+	require.Contains(t, err, "Usage: app [-q] [--[no-]cache]\n")
+	require.Contains(t, err, "  -c, --[no-]cache   Use the cache\n")
+}
+
+func TestPersistentOptionsHelp(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	app := App("app", "")
+	app.Bool(BoolOpt{Name: "v verbose", Desc: "Verbose output", Persistent: true})
+	app.Bool(BoolOpt{Name: "trace", Hidden: true, Persistent: true})
+	app.Command("build", "", func(cmd *Cmd) {
+		cmd.Bool(BoolOpt{Name: "f force", Desc: "Force"})
+	})
+
+	require.NoError(t, app.doInit())
+	app.PrintHelp()
+	require.NotContains(t, err, "Global options")
+
+	err = ""
+	build := app.commands[0]
+	require.NoError(t, build.doInit())
+	build.PrintHelp()
+
+	require.Contains(t, err, "Options:          \n  -f, --force     Force\n")
+	require.Contains(t, err, "Global options:   \n  -v, --verbose   Verbose output\n")
+	require.NotContains(t, err, "trace")
+}
+
+func TestHelpDoesNotInitSubCommands(t *testing.T) {
+	var out, err string
+	defer captureAndRestoreOutput(&out, &err)()
+
+	initialized := []string{}
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.Command("a", "A", func(cmd *Cmd) { initialized = append(initialized, "a") })
+	app.Command("b", "B", func(cmd *Cmd) { initialized = append(initialized, "b") }, HiddenCommand())
+	app.Command("c", "C", func(cmd *Cmd) {
+		initialized = append(initialized, "c")
+		cmd.Action = func() {}
+	})
+
+	require.NoError(t, app.Run([]string{"app", "--help"}))
+	require.Empty(t, initialized)
+	require.NotContains(t, err, "  b ")
+
+	require.NoError(t, app.Run([]string{"app", "c"}))
+	require.Equal(t, []string{"c"}, initialized)
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+/*
+WriteManPages generates a section 1 man page for the app and for each one of its commands and sub commands
+in the dir directory, using the app version (if set) as the pages source.
+
+The pages are named after the command path, e.g. docker.1, docker-run.1, docker-image-ls.1
+*/
+func (cli *Cli) WriteManPages(dir string) error {
+	source := cli.name
+	if cli.version != nil {
+		source = cli.version.version
+	}
+	return writeManPages(dir, cli.Cmd, source)
+}
+
+func writeManPages(dir string, c *Cmd, source string) error {
+	if err := c.doInit(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeManPage(&buf, c, source)
+	if err := ioutil.WriteFile(filepath.Join(dir, docName(c)+".1"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	for _, sub := range c.visibleCommands() {
+		if err := writeManPages(dir, sub, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// docName returns the name of the documentation page of a command, e.g. docker-image-ls
+func docName(c *Cmd) string {
+	return strings.Join(append(append([]string{}, c.parents...), c.name), "-")
+}
+
+func writeManPage(w io.Writer, c *Cmd, source string) {
+	help := c.help(true)
+	name := docName(c)
+
+	fmt.Fprintf(w, ".TH \"%s\" \"1\" \"\" \"%s\" \"User Commands\"\n", roffEscape(strings.ToUpper(name)), roffEscape(source))
+
+	fmt.Fprintf(w, ".SH NAME\n%s", roffEscape(name))
+	if c.desc != "" {
+		fmt.Fprintf(w, " \\- %s", roffEscape(c.desc))
+	}
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n", roffEscape(help.Path))
+	if synopsis := strings.TrimSpace(strings.TrimPrefix(help.Usage, help.Path)); synopsis != "" {
+		fmt.Fprintf(w, "%s\n", roffText(synopsis))
+	}
+
+	if len(help.Desc) > 0 {
+		fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roffText(help.Desc))
+	}
+
+	if len(help.Args) > 0 {
+		fmt.Fprintf(w, ".SH ARGUMENTS\n")
+		for _, section := range helpSections("", helpArgGroups(help.Args)) {
+			writeManSubsection(w, section.title)
+			for _, i := range section.indices {
+				arg := help.Args[i]
+				var (
+					deprecated = formatDeprecatedForHelp(arg.Deprecated)
+					env        = formatEnvVarsForHelp(arg.EnvVars, arg.DeprecatedEnvVars)
+					choices    = formatChoicesForHelp(arg.Choices)
+					value      = formatValueForHelp(arg.Value)
+				)
+				fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roffEscape(arg.Name), roffText(joinStrings(arg.Desc, deprecated, env, choices, value)))
+			}
+		}
+	}
+
+	if len(help.Options) > 0 {
+		fmt.Fprintf(w, ".SH OPTIONS\n")
+		for _, section := range helpSections("", helpOptionGroups(help.Options)) {
+			writeManSubsection(w, section.title)
+			for _, i := range section.indices {
+				opt := help.Options[i]
+				var (
+					deprecated = formatDeprecatedForHelp(opt.Deprecated)
+					required   = formatRequiredForHelp(opt.Required)
+					env        = formatEnvVarsForHelp(opt.EnvVars, opt.DeprecatedEnvVars)
+					choices    = formatChoicesForHelp(opt.Choices)
+					value      = formatValueForHelp(opt.Value)
+				)
+				fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roffEscape(strings.TrimSpace(formatOptNamesForHelp(opt.Names))), roffText(joinStrings(opt.Desc, deprecated, required, env, choices, value)))
+			}
+		}
+	}
+
+	envVars := []string{}
+	for _, opt := range help.Options {
+		for _, v := range opt.EnvVars {
+			envVars = append(envVars, fmt.Sprintf(".TP\n.B %s\n%s\n", roffEscape(v), roffText(joinStrings(
+				fmt.Sprintf("Sets the %s option.", opt.Names[len(opt.Names)-1]), formatDeprecatedEnvVarForHelp(v, opt.DeprecatedEnvVars)))))
+		}
+	}
+	for _, arg := range help.Args {
+		for _, v := range arg.EnvVars {
+			envVars = append(envVars, fmt.Sprintf(".TP\n.B %s\n%s\n", roffEscape(v), roffText(joinStrings(
+				fmt.Sprintf("Sets the %s argument.", arg.Name), formatDeprecatedEnvVarForHelp(v, arg.DeprecatedEnvVars)))))
+		}
+	}
+	if len(envVars) > 0 {
+		fmt.Fprintf(w, ".SH ENVIRONMENT\n%s", strings.Join(envVars, ""))
+	}
+
+	if len(help.Commands) > 0 {
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, sub := range help.Commands {
+			fmt.Fprintf(w, ".TP\n.B %s\n", roffEscape(strings.Join(sub.Aliases, ", ")))
+			if desc := joinStrings(sub.Desc, formatDeprecatedForHelp(sub.Deprecated)); desc != "" {
+				fmt.Fprintf(w, "%s\n", roffText(desc))
+			}
+		}
+	}
+
+	seeAlso := []string{}
+	for i := range c.parents {
+		seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s\\fR(1)", roffEscape(strings.Join(c.parents[:i+1], "-"))))
+	}
+	for _, sub := range help.Commands {
+		seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s\\fR(1)", roffEscape(name+"-"+sub.Aliases[0])))
+	}
+	if len(seeAlso) > 0 {
+		fmt.Fprintf(w, ".SH SEE ALSO\n%s\n", strings.Join(seeAlso, ", "))
+	}
+}
+
+// writeManSubsection starts a subsection for a group of options or arguments, or does nothing for the ungrouped ones
+func writeManSubsection(w io.Writer, title string) {
+	if title != "" {
+		fmt.Fprintf(w, ".SS %s\n", roffEscape(title))
+	}
+}
+
+var roffEscaper = strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+
+func roffEscape(s string) string {
+	return roffEscaper.Replace(s)
+}
+
+// roffText escapes a possibly multi-line text, protecting the lines which would otherwise be interpreted as requests
+func roffText(s string) string {
+	lines := strings.Split(roffEscape(s), "\n")
+	for i, l := range lines {
+		switch {
+		case l == "":
+			lines[i] = ".PP"
+		case strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'"):
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
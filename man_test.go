@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func manTestApp() *Cli {
+	app := App("app", "App Desc")
+	app.Version("v version", "app 1.0.0")
+	app.LongDesc = "Longer App Desc.\n\n.Lines starting with a dot are escaped"
+	app.Bool(BoolOpt{Name: "debug", EnvVar: "APP_DEBUG", Desc: "Enable debug-level logging"})
+	app.Bool(BoolOpt{Name: "trace", EnvVar: "APP_TRACE", Hidden: true})
+	app.Command("internal", "Internal commands", func(cmd *Cmd) {}, HiddenCommand())
+
+	app.Command("build b", "Build things", func(cmd *Cmd) {
+		cmd.Spec = "[-f=<FORMAT>] [--dry-run] SRC..."
+		cmd.Enum(EnumOpt{Name: "f format", Value: "json", Choices: []string{"json", "text"}, EnvVar: "APP_FORMAT BUILD_FORMAT", Desc: "Output format"})
+		cmd.Bool(BoolOpt{Name: "no-cache", Group: "Cache options", Desc: "Do not use the cache"})
+		cmd.Bool(BoolOpt{Name: "dry-run", Hidden: true})
+		cmd.Strings(StringsArg{Name: "SRC", EnvVar: "APP_SRC", Desc: "Source files"})
+
+		cmd.Command("image", "Build an image", func(cmd *Cmd) {})
+	})
+	return app
+}
+
+func TestWriteManPages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mow-man")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, manTestApp().WriteManPages(dir))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "app-build-image.1"),
+		filepath.Join(dir, "app-build.1"),
+		filepath.Join(dir, "app.1"),
+	}, files)
+
+	for _, page := range []string{"app.1", "app-build.1"} {
+		actual, err := ioutil.ReadFile(filepath.Join(dir, page))
+		require.NoError(t, err)
+
+		if *genGolden {
+			ioutil.WriteFile("testdata/man-"+page+".golden", actual, 0644)
+		}
+
+		expected, err := ioutil.ReadFile("testdata/man-" + page)
+		require.NoError(t, err, "Failed to read the expected man page from testdata/man-%s", page)
+
+		require.Equal(t, string(expected), string(actual))
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+/*
+WriteMarkdownDocs generates a Markdown documentation file for the app and for each one of its commands and sub commands
+in the dir directory.
+
+The files are named after the command path, e.g. docker.md, docker-run.md, docker-image-ls.md, and link to each other
+*/
+func (cli *Cli) WriteMarkdownDocs(dir string) error {
+	return writeMarkdownDocs(dir, cli.Cmd, nil)
+}
+
+func writeMarkdownDocs(dir string, c, parent *Cmd) error {
+	if err := c.doInit(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeMarkdownDoc(&buf, c, parent, func(path string) string { return strings.Replace(path, " ", "-", -1) + ".md" })
+	if err := ioutil.WriteFile(filepath.Join(dir, docName(c)+".md"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	for _, sub := range c.visibleCommands() {
+		if err := writeMarkdownDocs(dir, sub, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+WriteMarkdownDoc writes a single Markdown document to w, documenting the app and all its commands and sub commands,
+with links to the commands sections
+*/
+func (cli *Cli) WriteMarkdownDoc(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := writeCombinedMarkdownDoc(&buf, cli.Cmd, nil); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeCombinedMarkdownDoc(w io.Writer, c, parent *Cmd) error {
+	if err := c.doInit(); err != nil {
+		return err
+	}
+
+	writeMarkdownDoc(w, c, parent, func(path string) string { return "#" + markdownAnchor(path) })
+
+	for _, sub := range c.visibleCommands() {
+		fmt.Fprintf(w, "\n")
+		if err := writeCombinedMarkdownDoc(w, sub, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdownDoc documents a single command, link returning the target of a link to another command given its path
+func writeMarkdownDoc(out io.Writer, c, parent *Cmd, link func(path string) string) {
+	w := &bytes.Buffer{}
+	defer func() {
+		fmt.Fprintf(out, "%s\n", bytes.TrimRight(w.Bytes(), "\n"))
+	}()
+
+	help := c.help(false)
+	fmt.Fprintf(w, "# %s\n\n", help.Path)
+
+	if len(c.desc) > 0 {
+		fmt.Fprintf(w, "%s\n\n", c.desc)
+	}
+
+	fmt.Fprintf(w, "```\n%s\n```\n\n", help.Usage)
+
+	if len(c.LongDesc) > 0 {
+		fmt.Fprintf(w, "%s\n\n", c.LongDesc)
+	}
+
+	if len(help.Args) > 0 {
+		fmt.Fprintf(w, "## Arguments\n\n")
+		for _, section := range helpSections("", helpArgGroups(help.Args)) {
+			writeMarkdownTableHeader(w, section.title)
+			for _, i := range section.indices {
+				arg := help.Args[i]
+				desc := joinStrings(arg.Desc, formatDeprecatedForHelp(arg.Deprecated), formatChoicesForHelp(arg.Choices))
+				fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", arg.Name, markdownCell(desc), markdownEnvVars(arg.EnvVars, arg.DeprecatedEnvVars), markdownCode(arg.Value))
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	if len(help.Options) > 0 {
+		fmt.Fprintf(w, "## Options\n\n")
+		for _, section := range helpSections("", helpOptionGroups(help.Options)) {
+			writeMarkdownTableHeader(w, section.title)
+			for _, i := range section.indices {
+				opt := help.Options[i]
+				desc := joinStrings(opt.Desc, formatDeprecatedForHelp(opt.Deprecated), formatRequiredForHelp(opt.Required), formatChoicesForHelp(opt.Choices))
+				names := markdownCode(strings.TrimSpace(formatOptNamesForHelp(opt.Names)))
+				fmt.Fprintf(w, "| %s | %s | %s | %s |\n", names, markdownCell(desc), markdownEnvVars(opt.EnvVars, opt.DeprecatedEnvVars), markdownCode(opt.Value))
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	if len(help.Commands) > 0 {
+		fmt.Fprintf(w, "## Commands\n\n| Name | Description |\n| --- | --- |\n")
+		for _, sub := range help.Commands {
+			fmt.Fprintf(w, "| [%s](%s) | %s |\n", strings.Join(sub.Aliases, ", "), link(help.Path+" "+sub.Aliases[0]), markdownCell(joinStrings(sub.Desc, formatDeprecatedForHelp(sub.Deprecated))))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	if parent != nil {
+		fmt.Fprintf(w, "## See also\n\n* [%s](%s)", cmdPath(parent), link(cmdPath(parent)))
+		if len(parent.desc) > 0 {
+			fmt.Fprintf(w, " - %s", parent.desc)
+		}
+		fmt.Fprintf(w, "\n\n")
+	}
+}
+
+// writeMarkdownTableHeader starts the table of a group of options or arguments, preceded by the group title if any
+func writeMarkdownTableHeader(w io.Writer, title string) {
+	if title != "" {
+		fmt.Fprintf(w, "### %s\n\n", title)
+	}
+	fmt.Fprintf(w, "| Name | Description | Env | Default |\n| --- | --- | --- | --- |\n")
+}
+
+func cmdPath(c *Cmd) string {
+	return strings.Join(append(append([]string{}, c.parents...), c.name), " ")
+}
+
+func markdownEnvVars(envVars []string, deprecated map[string]string) string {
+	res := []string{}
+	for _, v := range envVars {
+		res = append(res, joinStrings(markdownCode("$"+v), markdownCell(formatDeprecatedEnvVarForHelp(v, deprecated))))
+	}
+	return strings.Join(res, ", ")
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.Replace(s, "|", `\|`, -1) + "`"
+}
+
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", "<br>")
+
+func markdownCell(s string) string {
+	return markdownCellEscaper.Replace(s)
+}
+
+// markdownAnchor returns the anchor generated for a heading, e.g. "docker image ls" -> "docker-image-ls"
+func markdownAnchor(heading string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return -1
+		}
+	}, heading)
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdownDocs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mow-md")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, manTestApp().WriteMarkdownDocs(dir))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "app-build-image.md"),
+		filepath.Join(dir, "app-build.md"),
+		filepath.Join(dir, "app.md"),
+	}, files)
+
+	actual, err := ioutil.ReadFile(filepath.Join(dir, "app-build.md"))
+	require.NoError(t, err)
+
+	if *genGolden {
+		ioutil.WriteFile("testdata/app-build.md.golden", actual, 0644)
+	}
+
+	expected, err := ioutil.ReadFile("testdata/app-build.md")
+	require.NoError(t, err, "Failed to read the expected documentation from testdata/app-build.md")
+
+	require.Equal(t, string(expected), string(actual))
+}
+
+func TestWriteMarkdownDoc(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, manTestApp().WriteMarkdownDoc(&out))
+
+	if *genGolden {
+		ioutil.WriteFile("testdata/app.md.golden", out.Bytes(), 0644)
+	}
+
+	expected, err := ioutil.ReadFile("testdata/app.md")
+	require.NoError(t, err, "Failed to read the expected documentation from testdata/app.md")
+
+	require.Equal(t, string(expected), out.String())
+}
+
+func TestMarkdownAnchor(t *testing.T) {
+	require.Equal(t, "docker-image-ls", markdownAnchor("docker image ls"))
+	require.Equal(t, "my_app-v2", markdownAnchor("My_App-v2!"))
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -49,11 +50,12 @@ func (arg *arg) match(args []string, c *parseContext) (bool, []string) {
 type optMatcher struct {
 	theOne     *opt
 	optionsIdx map[string]*opt
+	abbrev     bool
 }
 
 func (o *optMatcher) match(args []string, c *parseContext) (bool, []string) {
 	if len(args) == 0 || c.rejectOptions {
-		return o.theOne.valueSetFromEnv, args
+		return o.theOne.valueSetExternally(), args
 	}
 
 	idx := 0
@@ -63,7 +65,7 @@ func (o *optMatcher) match(args []string, c *parseContext) (bool, []string) {
 		case arg == "-":
 			idx++
 		case arg == "--":
-			return o.theOne.valueSetFromEnv, nil
+			return o.theOne.valueSetExternally(), nil
 		case strings.HasPrefix(arg, "--"):
 			matched, consumed, nargs := o.matchLongOpt(args, idx, c)
 
@@ -71,7 +73,7 @@ func (o *optMatcher) match(args []string, c *parseContext) (bool, []string) {
 				return true, nargs
 			}
 			if consumed == 0 {
-				return o.theOne.valueSetFromEnv, args
+				return o.theOne.valueSetExternally(), args
 			}
 			idx += consumed
 
@@ -81,22 +83,22 @@ func (o *optMatcher) match(args []string, c *parseContext) (bool, []string) {
 				return true, nargs
 			}
 			if consumed == 0 {
-				return o.theOne.valueSetFromEnv, args
+				return o.theOne.valueSetExternally(), args
 			}
 			idx += consumed
 
 		default:
-			return o.theOne.valueSetFromEnv, args
+			return o.theOne.valueSetExternally(), args
 		}
 	}
-	return o.theOne.valueSetFromEnv, args
+	return o.theOne.valueSetExternally(), args
 }
 
 func (o *optMatcher) matchLongOpt(args []string, idx int, c *parseContext) (bool, int, []string) {
 	arg := args[idx]
-	kv := strings.Split(arg, "=")
+	kv := strings.SplitN(arg, "=", 2)
 	name := kv[0]
-	opt, found := o.optionsIdx[name]
+	opt, name, found := lookupLongOpt(o.optionsIdx, name, o.abbrev)
 	if !found {
 		return false, 0, args
 	}
@@ -106,14 +108,21 @@ func (o *optMatcher) matchLongOpt(args []string, idx int, c *parseContext) (bool
 		if opt != o.theOne {
 			return false, 1, args
 		}
+		if opt.isNegatedName(name) {
+			return false, 0, args
+		}
 		value := kv[1]
 		c.opts[o.theOne] = append(c.opts[o.theOne], value)
 		return true, 1, removeStringAt(idx, args)
-	case opt.isBool():
+	case opt.isBool() || opt.optionalValue:
 		if opt != o.theOne {
 			return false, 1, args
 		}
-		c.opts[o.theOne] = append(c.opts[o.theOne], "true")
+		value, _ := opt.bareValue()
+		if opt.isNegatedName(name) {
+			value = "false"
+		}
+		c.opts[o.theOne] = append(c.opts[o.theOne], value)
 		return true, 1, removeStringAt(idx, args)
 	default:
 		if len(args[idx:]) < 2 {
@@ -131,6 +140,42 @@ func (o *optMatcher) matchLongOpt(args []string, idx int, c *parseContext) (bool
 	}
 }
 
+// lookupLongOpt finds the option named name, or, when abbrev is set, the only visible option
+// with a long name starting with name, together with its full matched name.
+// A bare -- (or a shorter name) is never an abbreviation
+func lookupLongOpt(optionsIdx map[string]*opt, name string, abbrev bool) (*opt, string, bool) {
+	if opt, found := optionsIdx[name]; found || !abbrev || len(name) <= 2 {
+		return opt, name, found
+	}
+
+	candidates := longOptCandidates(optionsIdx, name)
+	if len(candidates) != 1 {
+		return nil, name, false
+	}
+	return optionsIdx[candidates[0]], candidates[0], true
+}
+
+// longOptCandidates returns the sorted long names of the visible options starting with prefix, one per option
+func longOptCandidates(optionsIdx map[string]*opt, prefix string) []string {
+	names := []string{}
+	for name, opt := range optionsIdx {
+		if len(name) > 2 && strings.HasPrefix(name, prefix) && !opt.hidden {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	seen := map[*opt]bool{}
+	res := []string{}
+	for _, name := range names {
+		if opt := optionsIdx[name]; !seen[opt] {
+			seen[opt] = true
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
 func (o *optMatcher) matchShortOpt(args []string, idx int, c *parseContext) (bool, int, []string) {
 	arg := args[idx]
 	if len(arg) < 2 {
@@ -163,13 +208,13 @@ func (o *optMatcher) matchShortOpt(args []string, idx int, c *parseContext) (boo
 			return false, 0, args
 		}
 
-		if opt.isBool() {
+		if value, bare := opt.bareValue(); bare {
 			if opt != o.theOne {
 				remIdx++
 				continue
 			}
 
-			c.opts[o.theOne] = append(c.opts[o.theOne], "true")
+			c.opts[o.theOne] = append(c.opts[o.theOne], value)
 			newRem := rem[:remIdx] + rem[remIdx+1:]
 			if newRem == "" {
 				return true, 1, removeStringAt(idx, args)
@@ -220,6 +265,7 @@ func (o *optMatcher) matchShortOpt(args []string, idx int, c *parseContext) (boo
 type optsMatcher struct {
 	options      []*opt
 	optionsIndex map[string]*opt
+	abbrev       bool
 }
 
 func (om optsMatcher) try(args []string, c *parseContext) (bool, []string) {
@@ -230,8 +276,11 @@ func (om optsMatcher) try(args []string, c *parseContext) (bool, []string) {
 		if _, exclude := c.excludedOpts[o]; exclude {
 			continue
 		}
-		if ok, nargs := (&optMatcher{theOne: o, optionsIdx: om.optionsIndex}).match(args, c); ok {
-			if o.valueSetFromEnv {
+		matched := len(c.opts[o])
+		if ok, nargs := (&optMatcher{theOne: o, optionsIdx: om.optionsIndex, abbrev: om.abbrev}).match(args, c); ok {
+			// an option initialized from env or config matches even when absent from args: exclude it from
+			// further tries to avoid looping forever, but only when it didn't consume anything
+			if o.valueSetExternally() && len(c.opts[o]) == matched {
 				c.excludedOpts[o] = struct{}{}
 			}
 			return true, nargs
@@ -199,7 +199,7 @@ func TestOptsMatcher(t *testing.T) {
 func TestOptsMatcherInfiniteLoop(t *testing.T) {
 	opts := optsMatcher{
 		options: []*opt{
-			{names: []string{"-g"}, value: newStringValue(new(string), ""), valueSetFromEnv: true},
+			{names: []string{"-g"}, value: newStringValue(new(string), ""), valueSource: ValueSourceEnv},
 		},
 		optionsIndex: map[string]*opt{},
 	}
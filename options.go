@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // BoolOpt describes a boolean option
@@ -17,16 +18,41 @@ type BoolOpt struct {
 	EnvVar string
 	// The option's initial value
 	Value bool
+	// A boolean to also accept a --no-<name> form of each long name, which sets the option to false,
+	// e.g. to turn off an option which defaults to true or which was set from an env variable
+	Negatable bool
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(bool) error
 }
 
 func (o BoolOpt) value() bool {
 	return o.Value
 }
 
+func (o BoolOpt) validate(v bool) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
 // StringOpt describes a string option
 type StringOpt struct {
 	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
@@ -38,16 +64,45 @@ type StringOpt struct {
 	EnvVar string
 	// The option's initial value
 	Value string
+	// A boolean to make the option value optional: the bare --name form sets the option to ImplicitValue,
+	// and only the --name=value form sets another value
+	OptionalValue bool
+	// The value set by the bare form of an option with OptionalValue set
+	ImplicitValue string
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(string) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (o StringOpt) value() string {
 	return o.Value
 }
 
+func (o StringOpt) validate(v string) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
 // IntOpt describes an int option
 type IntOpt struct {
 	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
@@ -59,16 +114,45 @@ type IntOpt struct {
 	EnvVar string
 	// The option's initial value
 	Value int
+	// A boolean to make the option value optional: the bare --name form sets the option to ImplicitValue,
+	// and only the --name=value form sets another value
+	OptionalValue bool
+	// The value set by the bare form of an option with OptionalValue set
+	ImplicitValue int
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(int) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (o IntOpt) value() int {
 	return o.Value
 }
 
+func (o IntOpt) validate(v int) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
 // StringsOpt describes a string slice option
 type StringsOpt struct {
 	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
@@ -81,16 +165,40 @@ type StringsOpt struct {
 	EnvVar string
 	// The option's initial value
 	Value []string
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func([]string) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (o StringsOpt) value() []string {
 	return o.Value
 }
 
+func (o StringsOpt) validate(v []string) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
 // IntsOpt describes an int slice option
 type IntsOpt struct {
 	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
@@ -103,16 +211,422 @@ type IntsOpt struct {
 	EnvVar string
 	// The option's initial value
 	Value []int
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func([]int) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (o IntsOpt) value() []int {
 	return o.Value
 }
 
+func (o IntsOpt) validate(v []int) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+// FloatOpt describes a float64 option
+type FloatOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option
+	EnvVar string
+	// The option's initial value
+	Value float64
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(float64) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o FloatOpt) value() float64 {
+	return o.Value
+}
+
+func (o FloatOpt) validate(v float64) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+// FloatsOpt describes a float64 slice option
+type FloatsOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option.
+	// The env variable should contain a comma separated list of values
+	EnvVar string
+	// The option's initial value
+	Value []float64
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func([]float64) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o FloatsOpt) value() []float64 {
+	return o.Value
+}
+
+func (o FloatsOpt) validate(v []float64) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+// DurationOpt describes a time.Duration option
+type DurationOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option
+	EnvVar string
+	// The option's initial value
+	Value time.Duration
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(time.Duration) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o DurationOpt) value() time.Duration {
+	return o.Value
+}
+
+func (o DurationOpt) validate(v time.Duration) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+// DurationsOpt describes a time.Duration slice option
+type DurationsOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option.
+	// The env variable should contain a comma separated list of values
+	EnvVar string
+	// The option's initial value
+	Value []time.Duration
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func([]time.Duration) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o DurationsOpt) value() []time.Duration {
+	return o.Value
+}
+
+func (o DurationsOpt) validate(v []time.Duration) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+// CountOpt describes a counter option, i.e. a flag which doesn't take a value and which can be repeated, e.g. -vvv
+type CountOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option.
+	// The env variable should contain an integer
+	EnvVar string
+	// The option's initial value
+	Value int
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(int) error
+}
+
+func (o CountOpt) validate(v int) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+// EnumOpt describes a string option which only accepts a predefined set of values
+type EnumOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option
+	EnvVar string
+	// The option's initial value. If not empty, it must be one of the Choices
+	Value string
+	// The values accepted by this option
+	Choices []string
+	// A boolean to make the option value optional: the bare --name form sets the option to ImplicitValue,
+	// and only the --name=value form sets another value
+	OptionalValue bool
+	// The value set by the bare form of an option with OptionalValue set. It must be one of the Choices
+	ImplicitValue string
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(string) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o EnumOpt) value() string {
+	return o.Value
+}
+
+func (o EnumOpt) validate(v string) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+func (o EnumOpt) choices() []string {
+	return o.Choices
+}
+
+// EnumsOpt describes a string slice option which only accepts a predefined set of values
+type EnumsOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option.
+	// The env variable should contain a comma separated list of values
+	EnvVar string
+	// The option's initial value. Every value must be one of the Choices
+	Value []string
+	// The values accepted by this option
+	Choices []string
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func([]string) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o EnumsOpt) value() []string {
+	return o.Value
+}
+
+func (o EnumsOpt) validate(v []string) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+func (o EnumsOpt) choices() []string {
+	return o.Choices
+}
+
+// StringMapOpt describes a string map option, where every value is passed as key=value
+type StringMapOpt struct {
+	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+	// The one letter names will then be called with a single dash (short option), the others with two (long options).
+	Name string
+	// The option description as will be shown in help messages
+	Desc string
+	// A space separated list of environment variables names to be used to initialize this option.
+	// The env variable should contain a comma separated list of key=value pairs
+	EnvVar string
+	// The option's initial value
+	Value map[string]string
+	// If true, passing the same key more than once is an error. Otherwise, the last value wins
+	RejectDuplicateKeys bool
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
+	// A boolean to display or not the current value of the option in the help message
+	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
+	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
+	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(map[string]string) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
+}
+
+func (o StringMapOpt) value() map[string]string {
+	return o.Value
+}
+
+func (o StringMapOpt) validate(v map[string]string) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
+func (o StringMapOpt) rejectDuplicateKeys() bool {
+	return o.RejectDuplicateKeys
+}
+
 // VarOpt describes an option where the type and format of the value is controlled by the developer
 type VarOpt struct {
 	// A space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
@@ -124,16 +638,40 @@ type VarOpt struct {
 	EnvVar string
 	// A value implementing the flag.Value type (will hold the final value)
 	Value flag.Value
+	// A boolean to make the option mandatory when the spec is auto-generated (i.e. when the command Spec is empty)
+	Required bool
 	// A boolean to display or not the current value of the option in the help message
 	HideValue bool
+	// The help message section the option is listed under, e.g. "Output options". Ungrouped options are listed under "Options"
+	Group string
+	// A boolean to hide the option from the help messages, the generated documentation and the shell completion. It can still be used
+	Hidden bool
+	// A boolean to also accept the option after the name of any of the command sub commands, e.g. `app sub --verbose`.
+	// The option is listed under "Global options" in the sub commands help messages. A Required one is checked by the command which is run
+	Persistent bool
+	// A message to mark the option as deprecated, e.g. "use --endpoint instead". A warning is printed when it is used
+	Deprecated string
+	// Deprecation messages for some of the EnvVar names, e.g. {"HOST": "use $APP_ENDPOINT instead"}. A warning is printed when one of them is used
+	DeprecatedEnvVars map[string]string
 	// Set to true if this option was set by the user (as opposed to being set from env or not set at all)
 	SetByUser *bool
+	// A function called to validate the option's value once it was set from the call arguments or from an env variable
+	Validate func(flag.Value) error
+	// A function returning the completion candidates for the option's value starting with the given prefix
+	Complete func(prefix string) []string
 }
 
 func (o VarOpt) value() flag.Value {
 	return o.Value
 }
 
+func (o VarOpt) validate(v flag.Value) error {
+	if o.Validate == nil {
+		return nil
+	}
+	return o.Validate(v)
+}
+
 /*
 BoolOpt defines a boolean option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
 
@@ -219,6 +757,151 @@ func (c *Cmd) IntsOpt(name string, value []int, desc string) *[]int {
 	})
 }
 
+/*
+FloatOpt defines a float64 option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a float64) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) FloatOpt(name string, value float64, desc string) *float64 {
+	return c.Float(FloatOpt{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+FloatsOpt defines a float64 slice option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a float64 slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) FloatsOpt(name string, value []float64, desc string) *[]float64 {
+	return c.Floats(FloatsOpt{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+DurationOpt defines a time.Duration option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a time.Duration) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) DurationOpt(name string, value time.Duration, desc string) *time.Duration {
+	return c.Duration(DurationOpt{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+DurationsOpt defines a time.Duration slice option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a time.Duration slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) DurationsOpt(name string, value []time.Duration, desc string) *[]time.Duration {
+	return c.Durations(DurationsOpt{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+CountOpt defines a counter option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+A counter option doesn't take a value: its value is incremented every time it is present in the call arguments, e.g. `-v -v`, `-vv` and `--verbose -v` all yield 2.
+For the option to be accepted more than once, the spec should allow it, e.g. `[OPTIONS]` or `[-v...]`.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to an int) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) CountOpt(name string, value int, desc string) *int {
+	return c.Count(CountOpt{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
+/*
+EnumOpt defines a string option on the command c named `name`, with an initial value of `value`, a list of accepted values `choices`
+and a description of `desc` which will be used in help messages.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a string) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) EnumOpt(name string, value string, choices []string, desc string) *string {
+	return c.Enum(EnumOpt{
+		Name:    name,
+		Value:   value,
+		Choices: choices,
+		Desc:    desc,
+	})
+}
+
+/*
+EnumsOpt defines a string slice option on the command c named `name`, with an initial value of `value`, a list of accepted values `choices`
+and a description of `desc` which will be used in help messages.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a string slice) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) EnumsOpt(name string, value []string, choices []string, desc string) *[]string {
+	return c.Enums(EnumsOpt{
+		Name:    name,
+		Value:   value,
+		Choices: choices,
+		Desc:    desc,
+	})
+}
+
+/*
+StringMapOpt defines a string map option on the command c named `name`, with an initial value of `value` and a description of `desc` which will be used in help messages.
+
+Every occurrence of the option in the call arguments adds a key=value pair to the map, the last value winning if a key is repeated.
+
+The name is a space separated list of the option names *WITHOUT* the dashes, e.g. `f force` and *NOT* `-f --force`.
+The one letter names will then be called with a single dash (short option), the others with two (long options).
+
+
+The result should be stored in a variable (a pointer to a string map) which will be populated when the app is run and the call arguments get parsed
+*/
+func (c *Cmd) StringMapOpt(name string, value map[string]string, desc string) *map[string]string {
+	return c.StringMap(StringMapOpt{
+		Name:  name,
+		Value: value,
+		Desc:  desc,
+	})
+}
+
 /*
 VarOpt defines an option where the type and format is controlled by the developer.
 
@@ -233,14 +916,28 @@ func (c *Cmd) VarOpt(name string, value flag.Value, desc string) {
 }
 
 type opt struct {
-	name            string
-	desc            string
-	envVar          string
-	names           []string
-	hideValue       bool
-	valueSetFromEnv bool
-	valueSetByUser  *bool
-	value           flag.Value
+	name              string
+	desc              string
+	envVar            string
+	names             []string
+	required          bool
+	negatable         bool
+	negatedNames      []string
+	optionalValue     bool
+	implicitValue     string
+	hideValue         bool
+	group             string
+	hidden            bool
+	persistent        bool
+	deprecated        string
+	deprecatedEnvVars map[string]string
+	valueSource       ValueSource
+	valueEnvVar       string
+	configErr         error
+	valueSetByUser    *bool
+	value             flag.Value
+	validate          func() error
+	complete          func(prefix string) []string
 }
 
 func (o *opt) isBool() bool {
@@ -251,6 +948,57 @@ func (o *opt) isBool() bool {
 	return false
 }
 
+// isNegatedName returns true if name is the --no-<name> form of one of the option long names
+func (o *opt) isNegatedName(name string) bool {
+	for _, n := range o.negatedNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// helpNames returns the option names as shown in the help messages, i.e. with the long names of
+// a negatable option in the --[no-]name form
+func (o *opt) helpNames() []string {
+	if !o.negatable {
+		return o.names
+	}
+	res := []string{}
+	for _, n := range o.names {
+		if strings.HasPrefix(n, "--") {
+			n = "--[no-]" + n[2:]
+		}
+		res = append(res, n)
+	}
+	return res
+}
+
+// bareValue returns the value set by the bare form of the option, i.e. without a value, if it accepts one
+func (o *opt) bareValue() (string, bool) {
+	switch {
+	case o.isBool():
+		return "true", true
+	case o.optionalValue:
+		return o.implicitValue, true
+	default:
+		return "", false
+	}
+}
+
+func (o *opt) valueSetExternally() bool {
+	return o.valueSource == ValueSourceEnv || o.valueSource == ValueSourceConfig
+}
+
+func (o *opt) configName() string {
+	for _, n := range o.names {
+		if strings.HasPrefix(n, "--") {
+			return n[2:]
+		}
+	}
+	return strings.TrimLeft(o.names[0], "-")
+}
+
 func (o *opt) String() string {
 	return fmt.Sprintf("Opt(%v)", o.names)
 }
@@ -268,12 +1016,26 @@ func mkOptStrs(optName string) []string {
 }
 
 func (c *Cmd) mkOpt(opt opt) {
-	opt.valueSetFromEnv = setFromEnv(opt.value, opt.envVar)
+	if opt.valueEnvVar = setFromEnv(opt.value, opt.envVar); opt.valueEnvVar != "" {
+		opt.valueSource = ValueSourceEnv
+	}
 
 	opt.names = mkOptStrs(opt.name)
+	if opt.negatable {
+		for _, name := range opt.names {
+			if strings.HasPrefix(name, "--") {
+				opt.negatedNames = append(opt.negatedNames, "--no-"+name[2:])
+			}
+		}
+	}
+
+	c.setOptFromConfig(&opt)
 
 	c.options = append(c.options, &opt)
 	for _, name := range opt.names {
 		c.optionsIdx[name] = &opt
 	}
+	for _, name := range opt.negatedNames {
+		c.optionsIdx[name] = &opt
+	}
 }
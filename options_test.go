@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -139,3 +140,186 @@ func TestIntsOpt(t *testing.T) {
 	b = cmd.Ints(IntsOpt{Name: "b", Value: nil, EnvVar: "B C D E F", Desc: ""})
 	require.Equal(t, vi, *b)
 }
+
+func TestFloatOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	a := cmd.Float(FloatOpt{Name: "a", Value: -1.5, Desc: ""})
+	require.Equal(t, -1.5, *a)
+
+	os.Setenv("B", "")
+	b := cmd.Float(FloatOpt{Name: "b", Value: -1.5, EnvVar: "B", Desc: ""})
+	require.Equal(t, -1.5, *b)
+
+	goodValues := []float64{1, 0, 0.33}
+	for _, tv := range goodValues {
+		os.Setenv("B", strconv.FormatFloat(tv, 'g', -1, 64))
+		b := cmd.Float(FloatOpt{Name: "b", Value: -1.5, EnvVar: "B", Desc: ""})
+		require.Equal(t, tv, *b, "env=%v", tv)
+	}
+
+	badValues := []string{"", "b", "q1", "_"}
+	for _, tv := range badValues {
+		os.Setenv("B", tv)
+		b := cmd.Float(FloatOpt{Name: "b", Value: -1.5, EnvVar: "B", Desc: ""})
+		require.Equal(t, -1.5, *b, "env=%s", tv)
+	}
+
+	os.Setenv("B", "")
+	os.Setenv("C", "4.2")
+	os.Setenv("D", "666")
+	b = cmd.Float(FloatOpt{Name: "b", Value: -1.5, EnvVar: "B C D", Desc: ""})
+	require.Equal(t, 4.2, *b)
+}
+
+func TestFloatsOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	vf := []float64{4.2}
+	a := cmd.Floats(FloatsOpt{Name: "a", Value: vf, Desc: ""})
+	require.Equal(t, vf, *a)
+
+	os.Setenv("B", "")
+	b := cmd.Floats(FloatsOpt{Name: "b", Value: vf, EnvVar: "B", Desc: ""})
+	require.Equal(t, vf, *b)
+
+	os.Setenv("B", "0.5")
+	b = cmd.Floats(FloatsOpt{Name: "b", Value: nil, EnvVar: "B", Desc: ""})
+	require.Equal(t, []float64{0.5}, *b)
+
+	os.Setenv("B", "1, 2.5 , 3")
+	b = cmd.Floats(FloatsOpt{Name: "b", Value: nil, EnvVar: "B", Desc: ""})
+	require.Equal(t, []float64{1, 2.5, 3}, *b)
+
+	os.Setenv("B", "")
+	os.Setenv("C", "abc")
+	os.Setenv("D", "1, abc")
+	os.Setenv("E", "4.2")
+	os.Setenv("F", "666")
+	b = cmd.Floats(FloatsOpt{Name: "b", Value: nil, EnvVar: "B C D E F", Desc: ""})
+	require.Equal(t, vf, *b)
+}
+
+func TestDurationOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	a := cmd.Duration(DurationOpt{Name: "a", Value: time.Second, Desc: ""})
+	require.Equal(t, time.Second, *a)
+
+	os.Setenv("B", "")
+	b := cmd.Duration(DurationOpt{Name: "b", Value: time.Second, EnvVar: "B", Desc: ""})
+	require.Equal(t, time.Second, *b)
+
+	os.Setenv("B", "1m30s")
+	b = cmd.Duration(DurationOpt{Name: "b", Value: time.Second, EnvVar: "B", Desc: ""})
+	require.Equal(t, 90*time.Second, *b)
+
+	badValues := []string{"b", "12", "_"}
+	for _, tv := range badValues {
+		os.Setenv("B", tv)
+		b := cmd.Duration(DurationOpt{Name: "b", Value: time.Second, EnvVar: "B", Desc: ""})
+		require.Equal(t, time.Second, *b, "env=%s", tv)
+	}
+
+	os.Setenv("B", "")
+	os.Setenv("C", "5ms")
+	os.Setenv("D", "1h")
+	b = cmd.Duration(DurationOpt{Name: "b", Value: time.Second, EnvVar: "B C D", Desc: ""})
+	require.Equal(t, 5*time.Millisecond, *b)
+}
+
+func TestDurationsOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	vd := []time.Duration{time.Second}
+	a := cmd.Durations(DurationsOpt{Name: "a", Value: vd, Desc: ""})
+	require.Equal(t, vd, *a)
+
+	os.Setenv("B", "1s, 2m , 3h")
+	b := cmd.Durations(DurationsOpt{Name: "b", Value: nil, EnvVar: "B", Desc: ""})
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Minute, 3 * time.Hour}, *b)
+
+	os.Setenv("B", "")
+	os.Setenv("C", "abc")
+	os.Setenv("D", "1s, 2")
+	os.Setenv("E", "1s")
+	b = cmd.Durations(DurationsOpt{Name: "b", Value: nil, EnvVar: "B C D E", Desc: ""})
+	require.Equal(t, vd, *b)
+}
+
+func TestCountOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	a := cmd.Count(CountOpt{Name: "a", Value: 1, Desc: ""})
+	require.Equal(t, 1, *a)
+
+	os.Setenv("B", "")
+	b := cmd.Count(CountOpt{Name: "b", Value: 1, EnvVar: "B", Desc: ""})
+	require.Equal(t, 1, *b)
+
+	os.Setenv("B", "3")
+	b = cmd.Count(CountOpt{Name: "b", Value: 1, EnvVar: "B", Desc: ""})
+	require.Equal(t, 3, *b)
+
+	os.Setenv("B", "abc")
+	b = cmd.Count(CountOpt{Name: "b", Value: 1, EnvVar: "B", Desc: ""})
+	require.Equal(t, 1, *b)
+}
+
+func TestEnumOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	choices := []string{"json", "yaml", "table"}
+	a := cmd.Enum(EnumOpt{Name: "a", Value: "json", Choices: choices, Desc: ""})
+	require.Equal(t, "json", *a)
+
+	os.Setenv("B", "yaml")
+	b := cmd.Enum(EnumOpt{Name: "b", Value: "json", Choices: choices, EnvVar: "B", Desc: ""})
+	require.Equal(t, "yaml", *b)
+
+	os.Setenv("B", "xml")
+	b = cmd.Enum(EnumOpt{Name: "b", Value: "json", Choices: choices, EnvVar: "B", Desc: ""})
+	require.Equal(t, "json", *b)
+
+	require.Panics(t, func() {
+		cmd.Enum(EnumOpt{Name: "c", Value: "xml", Choices: choices, Desc: ""})
+	})
+}
+
+func TestEnumsOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	choices := []string{"a", "b", "c"}
+
+	os.Setenv("B", "a, c")
+	b := cmd.Enums(EnumsOpt{Name: "b", Choices: choices, EnvVar: "B", Desc: ""})
+	require.Equal(t, []string{"a", "c"}, *b)
+
+	os.Setenv("B", "")
+	os.Setenv("C", "a, x")
+	os.Setenv("D", "b")
+	b = cmd.Enums(EnumsOpt{Name: "b", Choices: choices, EnvVar: "B C D", Desc: ""})
+	require.Equal(t, []string{"b"}, *b)
+
+	require.Panics(t, func() {
+		cmd.Enums(EnumsOpt{Name: "c", Value: []string{"a", "x"}, Choices: choices, Desc: ""})
+	})
+}
+
+func TestStringMapOpt(t *testing.T) {
+	cmd := &Cmd{optionsIdx: map[string]*opt{}}
+	v := map[string]string{"a": "1"}
+	a := cmd.StringMap(StringMapOpt{Name: "a", Value: v, Desc: ""})
+	require.Equal(t, v, *a)
+
+	os.Setenv("B", "")
+	b := cmd.StringMap(StringMapOpt{Name: "b", Value: v, EnvVar: "B", Desc: ""})
+	require.Equal(t, v, *b)
+
+	os.Setenv("B", "k1=v1, k2=v2")
+	b = cmd.StringMap(StringMapOpt{Name: "b", Value: v, EnvVar: "B", Desc: ""})
+	require.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, *b)
+	require.Equal(t, map[string]string{"a": "1"}, v)
+
+	os.Setenv("B", "k1=v1,k1=v2")
+	b = cmd.StringMap(StringMapOpt{Name: "b", EnvVar: "B", Desc: ""})
+	require.Equal(t, map[string]string{"k1": "v2"}, *b)
+
+	os.Setenv("B", "k1=v1,k1=v2")
+	os.Setenv("C", "k=v")
+	b = cmd.StringMap(StringMapOpt{Name: "b", EnvVar: "B C", RejectDuplicateKeys: true, Desc: ""})
+	require.Equal(t, map[string]string{"k": "v"}, *b)
+}
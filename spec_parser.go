@@ -111,7 +111,7 @@ func (p *uParser) atom() (*state, *state) {
 			panic("No options after --")
 		}
 		end = newState(p.cmd)
-		start.t(optsMatcher{options: p.cmd.options, optionsIndex: p.cmd.optionsIdx}, end)
+		start.t(optsMatcher{options: p.cmd.options, optionsIndex: p.cmd.optionsIdx, abbrev: p.cmd.abbrev}, end)
 	case p.found(utShortOpt):
 		if p.rejectOptions {
 			p.back()
@@ -126,6 +126,7 @@ func (p *uParser) atom() (*state, *state) {
 		end = start.t(&optMatcher{
 			theOne:     opt,
 			optionsIdx: p.cmd.optionsIdx,
+			abbrev:     p.cmd.abbrev,
 		}, newState(p.cmd))
 		p.found(utOptValue)
 	case p.found(utLongOpt):
@@ -142,6 +143,7 @@ func (p *uParser) atom() (*state, *state) {
 		end = start.t(&optMatcher{
 			theOne:     opt,
 			optionsIdx: p.cmd.optionsIdx,
+			abbrev:     p.cmd.abbrev,
 		}, newState(p.cmd))
 		p.found(utOptValue)
 	case p.found(utOptSeq):
@@ -161,7 +163,7 @@ func (p *uParser) atom() (*state, *state) {
 			}
 			opts = append(opts, opt)
 		}
-		start.t(optsMatcher{options: opts, optionsIndex: p.cmd.optionsIdx}, end)
+		start.t(optsMatcher{options: opts, optionsIndex: p.cmd.optionsIdx, abbrev: p.cmd.abbrev}, end)
 	case p.found(utOpenPar):
 		start, end = p.seq(true)
 		p.expect(utClosePar)
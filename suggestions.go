@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultSuggestDistance is the maximum edit distance of the suggested commands and options unless set with Cli.Suggestions
+const defaultSuggestDistance = 2
+
+// unexpectedArg returns the first unknown option of args (before a --), or the first word in command position
+// which is not a sub command alias, or an empty string if there is none
+func (c *Cmd) unexpectedArg(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "-":
+		case strings.HasPrefix(arg, "-"):
+			if c.unknownOption(arg) {
+				return arg
+			}
+		case len(c.commands) > 0 && c.exactSubCommand(arg) == nil && c.inCommandPosition(args[:i]):
+			return arg
+		}
+	}
+	return ""
+}
+
+// unknownOption returns true if the option word arg, e.g. --name=value or -abc, starts with an undeclared option
+func (c *Cmd) unknownOption(arg string) bool {
+	if strings.HasPrefix(arg, "--") {
+		_, _, found := lookupLongOpt(c.optionsIdx, strings.SplitN(arg, "=", 2)[0], c.abbrev)
+		return !found
+	}
+
+	for _, name := range arg[1:] {
+		if name == '=' {
+			return false
+		}
+		opt, found := c.optionsIdx["-"+string(name)]
+		if !found {
+			return true
+		}
+		if _, bare := opt.bareValue(); !bare {
+			// the rest of the word is the option value
+			return false
+		}
+	}
+	return false
+}
+
+// printSuggestions prints the commands or the options whose names are close to the mistyped word arg, if any
+func (c *Cmd) printSuggestions(arg string) {
+	suggestions := c.suggestions(arg)
+	switch len(suggestions) {
+	case 0:
+	case 1:
+		fmt.Fprintf(stdErr, "Did you mean '%s'?\n", suggestions[0])
+	default:
+		fmt.Fprintf(stdErr, "Did you mean one of '%s'?\n", strings.Join(suggestions, "', '"))
+	}
+}
+
+// suggestions returns the visible long option names, including the inherited ones, if arg looks like a long option, or else the visible command names
+// (matching on any of their aliases) which are the closest to arg, within the configured edit distance
+func (c *Cmd) suggestions(arg string) []string {
+	if c.suggest <= 0 || arg == "" || arg == "-" || arg == "--" {
+		return nil
+	}
+	// an existing option or command was misused rather than mistyped
+	if _, found := c.optionsIdx[strings.SplitN(arg, "=", 2)[0]]; found || c.exactSubCommand(arg) != nil {
+		return nil
+	}
+
+	distances := map[string]int{}
+	res := []string{}
+	suggest := func(name string, aliases []string) {
+		for _, alias := range aliases {
+			d := editDistance(arg, alias)
+			if d > c.suggest {
+				continue
+			}
+			if prev, found := distances[name]; !found {
+				res = append(res, name)
+			} else if prev <= d {
+				continue
+			}
+			distances[name] = d
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		arg = strings.SplitN(arg, "=", 2)[0]
+		// the inherited persistent options only under the names they were indexed with, i.e. which are not shadowed
+		for _, o := range append(append([]*opt{}, c.options...), c.globalOptions...) {
+			if o.hidden {
+				continue
+			}
+			for _, n := range o.names {
+				if strings.HasPrefix(n, "--") && c.optionsIdx[n] == o {
+					suggest(n, []string{n})
+				}
+			}
+		}
+	case strings.HasPrefix(arg, "-"):
+		return nil
+	default:
+		for _, sub := range c.visibleCommands() {
+			suggest(sub.name, sub.aliases)
+		}
+	}
+
+	closest := []string{}
+	for _, name := range res {
+		switch {
+		case len(closest) == 0 || distances[name] == distances[closest[0]]:
+			closest = append(closest, name)
+		case distances[name] < distances[closest[0]]:
+			closest = []string{name}
+		}
+	}
+	return closest
+}
+
+// editDistance returns the optimal string alignment distance between a and b, i.e. the number of insertions,
+// deletions, substitutions and transpositions of adjacent characters needed to turn a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// ambiguityError returns an error listing the candidates when arg is an ambiguous abbreviation
+// of long options or sub commands, or err otherwise
+func (c *Cmd) ambiguityError(arg string, err error) error {
+	if !c.abbrev || arg == "" || arg == "-" || arg == "--" {
+		return err
+	}
+
+	what := "command"
+	var candidates []string
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		what = "option"
+		candidates = longOptCandidates(c.optionsIdx, strings.SplitN(arg, "=", 2)[0])
+	case strings.HasPrefix(arg, "-"):
+		return err
+	default:
+		candidates = c.subCommandCandidates(arg)
+	}
+
+	if len(candidates) < 2 {
+		return err
+	}
+	return fmt.Errorf("ambiguous %s %s, could be %s", what, arg, strings.Join(candidates, ", "))
+}
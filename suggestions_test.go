@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestions(t *testing.T) {
+	cases := []struct {
+		args       []string
+		distance   int
+		suggestion string
+	}{
+		{[]string{"app", "stauts"}, defaultSuggestDistance, "Did you mean 'status'?\n"},
+		{[]string{"app", "-v", "stats"}, defaultSuggestDistance, "Did you mean 'status'?\n"},
+		{[]string{"app", "star"}, defaultSuggestDistance, "Did you mean one of 'status', 'start'?\n"},
+		{[]string{"app", "--verbos"}, defaultSuggestDistance, "Did you mean '--verbose'?\n"},
+		{[]string{"app", "--verbos=true"}, defaultSuggestDistance, "Did you mean '--verbose'?\n"},
+		{[]string{"app", "status", "--forc"}, defaultSuggestDistance, "Did you mean '--force'?\n"},
+		{[]string{"app", "status", "--colr"}, defaultSuggestDistance, "Did you mean '--color'?\n"},
+		{[]string{"app", "status", "--colour"}, defaultSuggestDistance, "Did you mean '--color'?\n"},
+		{[]string{"app", "--trac"}, defaultSuggestDistance, ""},
+		{[]string{"app", "internl"}, defaultSuggestDistance, ""},
+		{[]string{"app", "-x"}, defaultSuggestDistance, ""},
+		{[]string{"app", "--user"}, defaultSuggestDistance, ""},
+		{[]string{"app", "--user", "x", "--verbos"}, defaultSuggestDistance, "Did you mean '--verbose'?\n"},
+		{[]string{"app", "--user", "x", "stauts"}, defaultSuggestDistance, "Did you mean 'status'?\n"},
+		{[]string{"app", "remote"}, defaultSuggestDistance, ""},
+		{[]string{"app", "stauts"}, 1, "Did you mean 'status'?\n"},
+		{[]string{"app", "stts"}, 1, ""},
+		{[]string{"app", "stauts"}, 0, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v with distance %d", cas.args, cas.distance)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Suggestions(cas.distance)
+		app.Bool(BoolOpt{Name: "v verbose"})
+		app.String(StringOpt{Name: "user"})
+		app.Bool(BoolOpt{Name: "trace", Hidden: true})
+		app.Bool(BoolOpt{Name: "color", Persistent: true})
+		app.Command("status stat", "", func(cmd *Cmd) {
+			cmd.Bool(BoolOpt{Name: "force"})
+			cmd.Action = func() {}
+		})
+		app.Command("start", "", func(cmd *Cmd) {
+			cmd.Action = func() {}
+		})
+		app.Command("internal", "", func(cmd *Cmd) {}, HiddenCommand())
+
+		require.Error(t, app.Run(cas.args))
+		restore()
+
+		require.Contains(t, err, "Error: incorrect usage\n"+cas.suggestion+"\nUsage:")
+	}
+}
+
+func TestSuggestionsWithRequiredArg(t *testing.T) {
+	cases := []struct {
+		args       []string
+		suggestion string
+	}{
+		{[]string{"app", "--verbos", "x"}, "Did you mean '--verbose'?\n"},
+		{[]string{"app", "x", "--verbos"}, "Did you mean '--verbose'?\n"},
+		{[]string{"app", "--user=a", "--verbos=true", "x"}, "Did you mean '--verbose'?\n"},
+		{[]string{"app", "-vz", "x"}, ""},
+		{[]string{"app", "--user"}, ""},
+		{[]string{"app", "x", "y"}, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("Testing %v", cas.args)
+
+		var out, err string
+		restore := captureAndRestoreOutput(&out, &err)
+
+		app := App("app", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Spec = "[OPTIONS] SRC"
+		app.Bool(BoolOpt{Name: "v verbose"})
+		app.String(StringOpt{Name: "u user"})
+		app.String(StringArg{Name: "SRC"})
+		app.Action = func() {}
+
+		require.Error(t, app.Run(cas.args))
+		restore()
+
+		require.Contains(t, err, "Error: incorrect usage\n"+cas.suggestion+"\nUsage:")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"stauts", "status", 1},
+		{"stauts", "start", 2},
+		{"--verbos", "--verbose", 1},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 3},
+		{"héllo", "hello", 1},
+	}
+
+	for _, cas := range cases {
+		require.Equal(t, cas.expected, editDistance(cas.a, cas.b), "distance between %q and %q", cas.a, cas.b)
+	}
+}
@@ -2,11 +2,12 @@ package cli
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"strings"
 )
 
-func setFromEnv(into flag.Value, envVars string) bool {
+func setFromEnv(into flag.Value, envVars string) string {
 	multiValued, isMulti := into.(multiValued)
 
 	if len(envVars) > 0 {
@@ -22,27 +23,43 @@ func setFromEnv(into flag.Value, envVars string) bool {
 			}
 			if !isMulti {
 				if err := into.Set(v); err == nil {
-					return true
+					return ev
 				}
 				continue
 			}
 
 			vs := strings.Split(v, ",")
 			if err := setMultivalued(multiValued, vs); err == nil {
-				return true
+				return ev
 			}
 		}
 	}
-	return false
+	return ""
+}
+
+func warnDeprecated(what, msg string) {
+	if msg == "" {
+		fmt.Fprintf(stdErr, "Warning: %s is deprecated\n", what)
+		return
+	}
+	fmt.Fprintf(stdErr, "Warning: %s is deprecated: %s\n", what, msg)
+}
+
+func warnInvalidConfig(what string, err error) {
+	fmt.Fprintf(stdErr, "Warning: ignoring the config value of %s: %s\n", what, err.Error())
 }
 
 func setMultivalued(into multiValued, values []string) error {
+	restore := into.Clear
+	if restorable, ok := into.(restorableValue); ok {
+		restore = restorable.save()
+	}
 	into.Clear()
 
 	for _, v := range values {
 		v = strings.TrimSpace(v)
 		if err := into.Set(v); err != nil {
-			into.Clear()
+			restore()
 			return err
 		}
 	}
@@ -28,3 +28,23 @@ func TestJoinStrings(t *testing.T) {
 		require.Equal(t, cas.expected, actual)
 	}
 }
+
+func TestSetMultivaluedKeepsValueOnError(t *testing.T) {
+	var ints []int
+	intsParam := newIntsValue(&ints, []int{1, 2})
+	require.Error(t, setMultivalued(intsParam, []string{"3", "x"}))
+	require.Equal(t, []int{1, 2}, ints)
+
+	var enums []string
+	enumsParam := newEnumsValue(&enums, []string{"a"}, []string{"a", "b"})
+	require.Error(t, setMultivalued(enumsParam, []string{"b", "c"}))
+	require.Equal(t, []string{"a"}, enums)
+
+	var kvs map[string]string
+	kvsParam := newStringMapValue(&kvs, map[string]string{"a": "1"}, false)
+	require.Error(t, setMultivalued(kvsParam, []string{"b=2", "c"}))
+	require.Equal(t, map[string]string{"a": "1"}, kvs)
+
+	require.NoError(t, setMultivalued(intsParam, []string{"3", "4"}))
+	require.Equal(t, []int{3, 4}, ints)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import "fmt"
+
+/*
+ValueSource describes where the value of an option or an argument came from
+*/
+type ValueSource int
+
+const (
+	// ValueSourceDefault means that the value is the initial value set in the option or argument declaration
+	ValueSourceDefault ValueSource = iota
+	// ValueSourceEnv means that the value was read from an environment variable
+	ValueSourceEnv
+	// ValueSourceConfig means that the value was read from the app's ConfigSource
+	ValueSourceConfig
+	// ValueSourceCommandLine means that the value was set in the call arguments
+	ValueSourceCommandLine
+)
+
+func (s ValueSource) String() string {
+	switch s {
+	case ValueSourceDefault:
+		return "default"
+	case ValueSourceEnv:
+		return "env"
+	case ValueSourceConfig:
+		return "config"
+	case ValueSourceCommandLine:
+		return "command line"
+	default:
+		return fmt.Sprintf("ValueSource(%d)", int(s))
+	}
+}
+
+/*
+OptionValueSource returns where the value of the option named `name` came from, and if it came from an environment
+variable, the name of that variable.
+
+name is any of the option names, with or without the dashes, e.g. `f`, `-f`, `force` or `--force`.
+It panics if the command doesn't declare such an option.
+
+In most cases, this method should be called from the Action func, i.e. after the call arguments were parsed.
+*/
+func (c *Cmd) OptionValueSource(name string) (ValueSource, string) {
+	if len(name) > 0 && name[0] != '-' {
+		name = mkOptStrs(name)[0]
+	}
+	opt, found := c.optionsIdx[name]
+	if !found {
+		panic(fmt.Sprintf("Undeclared option %s", name))
+	}
+	return opt.valueSource, opt.valueEnvVar
+}
+
+/*
+ArgValueSource returns where the value of the argument named `name` came from, and if it came from an environment
+variable, the name of that variable.
+
+It panics if the command doesn't declare such an argument.
+
+In most cases, this method should be called from the Action func, i.e. after the call arguments were parsed.
+*/
+func (c *Cmd) ArgValueSource(name string) (ValueSource, string) {
+	arg, found := c.argsIdx[name]
+	if !found {
+		panic(fmt.Sprintf("Undeclared arg %s", name))
+	}
+	return arg.valueSource, arg.valueEnvVar
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueSources(t *testing.T) {
+	os.Setenv("MOW_A1", "")
+	os.Setenv("MOW_A2", "from-env")
+	os.Setenv("MOW_ARG", "from-env")
+
+	source, err := JSONConfig(strings.NewReader(`{"app": {"b": "from-config", "c": "from-config"}}`))
+	require.NoError(t, err)
+
+	app := App("app", "")
+	app.ErrorHandling = flag.ContinueOnError
+	app.Config(source)
+	app.Spec = "[OPTIONS] [ARG]"
+
+	app.String(StringOpt{Name: "a", EnvVar: "MOW_A1 MOW_A2"})
+	app.String(StringOpt{Name: "b"})
+	app.String(StringOpt{Name: "c"})
+	app.String(StringOpt{Name: "d default"})
+	app.String(StringArg{Name: "ARG", EnvVar: "MOW_ARG"})
+
+	ex := false
+	app.Action = func() {
+		ex = true
+
+		src, env := app.OptionValueSource("a")
+		require.Equal(t, ValueSourceEnv, src)
+		require.Equal(t, "MOW_A2", env)
+
+		src, env = app.OptionValueSource("-b")
+		require.Equal(t, ValueSourceConfig, src)
+		require.Equal(t, "", env)
+
+		src, _ = app.OptionValueSource("c")
+		require.Equal(t, ValueSourceCommandLine, src)
+
+		src, _ = app.OptionValueSource("default")
+		require.Equal(t, ValueSourceDefault, src)
+		src, _ = app.OptionValueSource("--default")
+		require.Equal(t, ValueSourceDefault, src)
+
+		src, env = app.ArgValueSource("ARG")
+		require.Equal(t, ValueSourceEnv, src)
+		require.Equal(t, "MOW_ARG", env)
+	}
+
+	require.NoError(t, app.Run([]string{"app", "-c", "x"}))
+	require.True(t, ex, "Exec wasn't called")
+
+	require.Panics(t, func() { app.OptionValueSource("x") })
+	require.Panics(t, func() { app.ArgValueSource("X") })
+}
+
+func TestValueSourceString(t *testing.T) {
+	require.Equal(t, "default", ValueSourceDefault.String())
+	require.Equal(t, "env", ValueSourceEnv.String())
+	require.Equal(t, "config", ValueSourceConfig.String())
+	require.Equal(t, "command line", ValueSourceCommandLine.String())
+}
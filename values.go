@@ -3,7 +3,10 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type boolValued interface {
@@ -16,10 +19,20 @@ type multiValued interface {
 	Clear()
 }
 
+// restorableValue is implemented by the multi-valued values able to save their current content,
+// returning a function which puts it back, e.g. when setting new values fails midway
+type restorableValue interface {
+	save() func()
+}
+
 type defaultValued interface {
 	IsDefault() bool
 }
 
+type choicesValued interface {
+	Choices() []string
+}
+
 /******************************************************************************/
 /* BOOL                                                                        */
 /******************************************************************************/
@@ -115,6 +128,53 @@ func (ia *intValue) String() string {
 	return fmt.Sprintf("%v", *ia)
 }
 
+/******************************************************************************/
+/* COUNT                                                                      */
+/******************************************************************************/
+
+// countValue is an int which behaves like a bool flag on the command line:
+// every time the option is matched the value is incremented, so -vvv yields 3.
+type countValue int
+
+var (
+	_ flag.Value    = newCountValue(new(int), 0)
+	_ boolValued    = newCountValue(new(int), 0)
+	_ defaultValued = newCountValue(new(int), 0)
+)
+
+func newCountValue(into *int, v int) *countValue {
+	*into = v
+	return (*countValue)(into)
+}
+
+func (ca *countValue) Set(s string) error {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*ca = countValue(int(i))
+		return nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if b {
+		*ca++
+	}
+	return nil
+}
+
+func (ca *countValue) IsBoolFlag() bool {
+	return true
+}
+
+func (ca *countValue) String() string {
+	return fmt.Sprintf("%v", *ca)
+}
+
+func (ca *countValue) IsDefault() bool {
+	return int(*ca) == 0
+}
+
 /******************************************************************************/
 /* STRINGS                                                                    */
 /******************************************************************************/
@@ -153,6 +213,11 @@ func (sa *stringsValue) Clear() {
 	*sa = nil
 }
 
+func (sa *stringsValue) save() func() {
+	saved := *sa
+	return func() { *sa = saved }
+}
+
 func (sa *stringsValue) IsDefault() bool {
 	return len(*sa) == 0
 }
@@ -198,6 +263,338 @@ func (ia *intsValue) Clear() {
 	*ia = nil
 }
 
+func (ia *intsValue) save() func() {
+	saved := *ia
+	return func() { *ia = saved }
+}
+
 func (ia *intsValue) IsDefault() bool {
 	return len(*ia) == 0
 }
+
+/******************************************************************************/
+/* FLOAT                                                                      */
+/******************************************************************************/
+
+type floatValue float64
+
+var (
+	_ flag.Value = newFloatValue(new(float64), 0)
+)
+
+func newFloatValue(into *float64, v float64) *floatValue {
+	*into = v
+	return (*floatValue)(into)
+}
+
+func (fa *floatValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*fa = floatValue(f)
+	return nil
+}
+
+func (fa *floatValue) String() string {
+	return strconv.FormatFloat(float64(*fa), 'g', -1, 64)
+}
+
+/******************************************************************************/
+/* FLOATS                                                                     */
+/******************************************************************************/
+
+type floatsValue []float64
+
+var (
+	_ flag.Value    = newFloatsValue(new([]float64), nil)
+	_ multiValued   = newFloatsValue(new([]float64), nil)
+	_ defaultValued = newFloatsValue(new([]float64), nil)
+)
+
+func newFloatsValue(into *[]float64, v []float64) *floatsValue {
+	*into = v
+	return (*floatsValue)(into)
+}
+
+func (fa *floatsValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*fa = append(*fa, f)
+	return nil
+}
+
+func (fa *floatsValue) String() string {
+	res := "["
+	for idx, f := range *fa {
+		if idx > 0 {
+			res += ", "
+		}
+		res += strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return res + "]"
+}
+
+func (fa *floatsValue) Clear() {
+	*fa = nil
+}
+
+func (fa *floatsValue) save() func() {
+	saved := *fa
+	return func() { *fa = saved }
+}
+
+func (fa *floatsValue) IsDefault() bool {
+	return len(*fa) == 0
+}
+
+/******************************************************************************/
+/* DURATION                                                                   */
+/******************************************************************************/
+
+type durationValue time.Duration
+
+var (
+	_ flag.Value    = newDurationValue(new(time.Duration), 0)
+	_ defaultValued = newDurationValue(new(time.Duration), 0)
+)
+
+func newDurationValue(into *time.Duration, v time.Duration) *durationValue {
+	*into = v
+	return (*durationValue)(into)
+}
+
+func (da *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*da = durationValue(d)
+	return nil
+}
+
+func (da *durationValue) String() string {
+	return time.Duration(*da).String()
+}
+
+func (da *durationValue) IsDefault() bool {
+	return time.Duration(*da) == 0
+}
+
+/******************************************************************************/
+/* DURATIONS                                                                  */
+/******************************************************************************/
+
+type durationsValue []time.Duration
+
+var (
+	_ flag.Value    = newDurationsValue(new([]time.Duration), nil)
+	_ multiValued   = newDurationsValue(new([]time.Duration), nil)
+	_ defaultValued = newDurationsValue(new([]time.Duration), nil)
+)
+
+func newDurationsValue(into *[]time.Duration, v []time.Duration) *durationsValue {
+	*into = v
+	return (*durationsValue)(into)
+}
+
+func (da *durationsValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*da = append(*da, d)
+	return nil
+}
+
+func (da *durationsValue) String() string {
+	res := "["
+	for idx, d := range *da {
+		if idx > 0 {
+			res += ", "
+		}
+		res += d.String()
+	}
+	return res + "]"
+}
+
+func (da *durationsValue) Clear() {
+	*da = nil
+}
+
+func (da *durationsValue) save() func() {
+	saved := *da
+	return func() { *da = saved }
+}
+
+func (da *durationsValue) IsDefault() bool {
+	return len(*da) == 0
+}
+
+/******************************************************************************/
+/* ENUM                                                                       */
+/******************************************************************************/
+
+type enumValue struct {
+	into    *string
+	choices []string
+}
+
+var (
+	_ flag.Value    = newEnumValue(new(string), "", nil)
+	_ defaultValued = newEnumValue(new(string), "", nil)
+	_ choicesValued = newEnumValue(new(string), "", nil)
+)
+
+func newEnumValue(into *string, v string, choices []string) *enumValue {
+	*into = v
+	return &enumValue{into: into, choices: choices}
+}
+
+func (ea *enumValue) Set(s string) error {
+	if err := checkChoice(s, ea.choices); err != nil {
+		return err
+	}
+	*ea.into = s
+	return nil
+}
+
+func (ea *enumValue) String() string {
+	return fmt.Sprintf("%#v", *ea.into)
+}
+
+func (ea *enumValue) IsDefault() bool {
+	return *ea.into == ""
+}
+
+func (ea *enumValue) Choices() []string {
+	return ea.choices
+}
+
+/******************************************************************************/
+/* ENUMS                                                                      */
+/******************************************************************************/
+
+type enumsValue struct {
+	into    *[]string
+	choices []string
+}
+
+var (
+	_ flag.Value    = newEnumsValue(new([]string), nil, nil)
+	_ multiValued   = newEnumsValue(new([]string), nil, nil)
+	_ defaultValued = newEnumsValue(new([]string), nil, nil)
+	_ choicesValued = newEnumsValue(new([]string), nil, nil)
+)
+
+func newEnumsValue(into *[]string, v []string, choices []string) *enumsValue {
+	*into = v
+	return &enumsValue{into: into, choices: choices}
+}
+
+func (ea *enumsValue) Set(s string) error {
+	if err := checkChoice(s, ea.choices); err != nil {
+		return err
+	}
+	*ea.into = append(*ea.into, s)
+	return nil
+}
+
+func (ea *enumsValue) String() string {
+	return (*stringsValue)(ea.into).String()
+}
+
+func (ea *enumsValue) Clear() {
+	*ea.into = nil
+}
+
+func (ea *enumsValue) save() func() {
+	saved := *ea.into
+	return func() { *ea.into = saved }
+}
+
+func (ea *enumsValue) IsDefault() bool {
+	return len(*ea.into) == 0
+}
+
+func (ea *enumsValue) Choices() []string {
+	return ea.choices
+}
+
+func checkChoice(s string, choices []string) error {
+	for _, c := range choices {
+		if s == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %q, must be one of %s", s, strings.Join(choices, ", "))
+}
+
+/******************************************************************************/
+/* STRING MAP                                                                 */
+/******************************************************************************/
+
+type stringMapValue struct {
+	into             *map[string]string
+	rejectDuplicates bool
+}
+
+var (
+	_ flag.Value    = newStringMapValue(new(map[string]string), nil, false)
+	_ multiValued   = newStringMapValue(new(map[string]string), nil, false)
+	_ defaultValued = newStringMapValue(new(map[string]string), nil, false)
+)
+
+func newStringMapValue(into *map[string]string, v map[string]string, rejectDuplicates bool) *stringMapValue {
+	*into = v
+	return &stringMapValue{into: into, rejectDuplicates: rejectDuplicates}
+}
+
+func (ma *stringMapValue) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("invalid value %q, expected key=value", s)
+	}
+	k, v := kv[0], kv[1]
+
+	if *ma.into == nil {
+		*ma.into = map[string]string{}
+	}
+	if _, found := (*ma.into)[k]; found && ma.rejectDuplicates {
+		return fmt.Errorf("duplicate key %q", k)
+	}
+	(*ma.into)[k] = v
+	return nil
+}
+
+func (ma *stringMapValue) String() string {
+	keys := make([]string, 0, len(*ma.into))
+	for k := range *ma.into {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := "{"
+	for idx, k := range keys {
+		if idx > 0 {
+			res += ", "
+		}
+		res += fmt.Sprintf("%#v: %#v", k, (*ma.into)[k])
+	}
+	return res + "}"
+}
+
+func (ma *stringMapValue) Clear() {
+	*ma.into = nil
+}
+
+func (ma *stringMapValue) save() func() {
+	saved := *ma.into
+	return func() { *ma.into = saved }
+}
+
+func (ma *stringMapValue) IsDefault() bool {
+	return len(*ma.into) == 0
+}
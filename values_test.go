@@ -2,6 +2,7 @@ package cli
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -135,3 +136,207 @@ func TestIntsParam(t *testing.T) {
 
 	require.Empty(t, into)
 }
+
+func TestFloatParam(t *testing.T) {
+	var into float64
+
+	param := newFloatValue(&into, 0)
+
+	cases := []struct {
+		input  string
+		err    bool
+		result float64
+		string string
+	}{
+		{"12", false, 12, "12"},
+		{"0", false, 0, "0"},
+		{"0.5", false, 0.5, "0.5"},
+		{"-1.25", false, -1.25, "-1.25"},
+		{"1e3", false, 1000, "1000"},
+		{"", true, 0, ""},
+		{"abc", true, 0, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("testing with %q", cas.input)
+
+		err := param.Set(cas.input)
+
+		if cas.err {
+			require.Errorf(t, err, "value %q should have returned an error", cas.input)
+			continue
+		}
+
+		require.Equal(t, cas.result, into)
+		require.Equal(t, cas.string, param.String())
+	}
+}
+
+func TestFloatsParam(t *testing.T) {
+	into := []float64{}
+	param := newFloatsValue(&into, nil)
+
+	err := param.Set("1.5")
+	require.NoError(t, err)
+
+	err = param.Set("2")
+	require.NoError(t, err)
+
+	require.Equal(t, []float64{1.5, 2}, into)
+
+	require.Equal(t, `[1.5, 2]`, param.String())
+
+	err = param.Set("c")
+	require.Error(t, err)
+	require.Equal(t, []float64{1.5, 2}, into)
+
+	param.Clear()
+
+	require.Empty(t, into)
+}
+
+func TestDurationParam(t *testing.T) {
+	var into time.Duration
+
+	param := newDurationValue(&into, 0)
+	require.True(t, param.IsDefault())
+
+	cases := []struct {
+		input  string
+		err    bool
+		result time.Duration
+		string string
+	}{
+		{"30s", false, 30 * time.Second, "30s"},
+		{"1h2m", false, time.Hour + 2*time.Minute, "1h2m0s"},
+		{"0", false, 0, "0s"},
+		{"", true, 0, ""},
+		{"12", true, 0, ""},
+		{"abc", true, 0, ""},
+	}
+
+	for _, cas := range cases {
+		t.Logf("testing with %q", cas.input)
+
+		err := param.Set(cas.input)
+
+		if cas.err {
+			require.Errorf(t, err, "value %q should have returned an error", cas.input)
+			continue
+		}
+
+		require.Equal(t, cas.result, into)
+		require.Equal(t, cas.string, param.String())
+	}
+}
+
+func TestDurationsParam(t *testing.T) {
+	into := []time.Duration{}
+	param := newDurationsValue(&into, nil)
+
+	err := param.Set("1s")
+	require.NoError(t, err)
+
+	err = param.Set("2m")
+	require.NoError(t, err)
+
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, into)
+
+	require.Equal(t, `[1s, 2m0s]`, param.String())
+
+	err = param.Set("c")
+	require.Error(t, err)
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, into)
+
+	param.Clear()
+
+	require.Empty(t, into)
+}
+
+func TestCountParam(t *testing.T) {
+	var into int
+
+	param := newCountValue(&into, 0)
+
+	require.True(t, param.IsBoolFlag())
+	require.True(t, param.IsDefault())
+
+	require.NoError(t, param.Set("true"))
+	require.NoError(t, param.Set("true"))
+	require.Equal(t, 2, into)
+	require.Equal(t, "2", param.String())
+
+	require.NoError(t, param.Set("false"))
+	require.Equal(t, 2, into)
+
+	require.NoError(t, param.Set("5"))
+	require.Equal(t, 5, into)
+
+	require.Error(t, param.Set("abc"))
+	require.Equal(t, 5, into)
+}
+
+func TestEnumParam(t *testing.T) {
+	var into string
+
+	param := newEnumValue(&into, "", []string{"json", "yaml"})
+
+	require.True(t, param.IsDefault())
+	require.Equal(t, []string{"json", "yaml"}, param.Choices())
+
+	require.NoError(t, param.Set("yaml"))
+	require.Equal(t, "yaml", into)
+	require.Equal(t, `"yaml"`, param.String())
+
+	require.Error(t, param.Set("xml"))
+	require.Equal(t, "yaml", into)
+}
+
+func TestEnumsParam(t *testing.T) {
+	into := []string{}
+	param := newEnumsValue(&into, nil, []string{"a", "b"})
+
+	require.NoError(t, param.Set("a"))
+	require.NoError(t, param.Set("b"))
+	require.Equal(t, []string{"a", "b"}, into)
+	require.Equal(t, `["a", "b"]`, param.String())
+
+	require.Error(t, param.Set("c"))
+	require.Equal(t, []string{"a", "b"}, into)
+
+	param.Clear()
+
+	require.Empty(t, into)
+}
+
+func TestStringMapParam(t *testing.T) {
+	into := map[string]string{}
+	param := newStringMapValue(&into, nil, false)
+
+	require.True(t, param.IsDefault())
+
+	require.NoError(t, param.Set("b=2"))
+	require.NoError(t, param.Set("a=x=y"))
+	require.NoError(t, param.Set("c="))
+	require.Equal(t, map[string]string{"a": "x=y", "b": "2", "c": ""}, into)
+	require.Equal(t, `{"a": "x=y", "b": "2", "c": ""}`, param.String())
+
+	require.NoError(t, param.Set("b=3"))
+	require.Equal(t, "3", into["b"])
+
+	require.Error(t, param.Set("b"))
+	require.Error(t, param.Set("=b"))
+
+	param.Clear()
+
+	require.Empty(t, into)
+}
+
+func TestStringMapParamRejectDuplicates(t *testing.T) {
+	into := map[string]string{}
+	param := newStringMapValue(&into, nil, true)
+
+	require.NoError(t, param.Set("a=1"))
+	require.Error(t, param.Set("a=2"))
+	require.Equal(t, map[string]string{"a": "1"}, into)
+}